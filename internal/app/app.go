@@ -9,32 +9,93 @@ import (
 	"evil-rkn/internal/registry"
 	"evil-rkn/internal/transport/grpc"
 	httpgw "evil-rkn/internal/transport/http"
+	"evil-rkn/internal/webhook"
 
 	"golang.org/x/sync/errgroup"
 )
 
 func Run(ctx context.Context, cfg config.Config) error {
 	holder := registry.NewHolder()
-	client := registry.NewClient(cfg.RKNAPIBaseURL)
+	if cfg.SnapshotPath != "" {
+		// Serve the last known registry right away, the updater
+		// replaces it once the upstream answers.
+		if reg, err := registry.LoadSnapshot(cfg.SnapshotPath); err != nil {
+			log.Printf("app: starting without snapshot: %v", err)
+		} else {
+			holder.Set(reg)
+			log.Printf("app: restored registry from snapshot, updated %s", reg.LastUpdated.Format(time.RFC3339))
+		}
+	}
+	src, err := registry.NewSources(cfg.RegistrySources, cfg.SourceCrossCheck, cfg.DumpFetchTimeout)
+	if err != nil {
+		return err
+	}
+
+	guard, err := registry.NewGuard(registry.GuardConfig{
+		MaxDrop:       cfg.GuardMaxDrop,
+		MaxGrowth:     cfg.GuardMaxGrowth,
+		MinEntries:    cfg.GuardMinEntries,
+		RequiredKinds: cfg.GuardRequiredKinds,
+	})
+	if err != nil {
+		return err
+	}
 
+	trigger := registry.NewTrigger()
+	monitor := registry.NewMonitor()
 	updCfg := registry.Config{
 		Interval:       cfg.UpdateInterval,
 		InitialBackoff: 30 * time.Second,
 		MaxBackoff:     30 * time.Minute,
+		SnapshotPath:   cfg.SnapshotPath,
+		Guard:          guard,
+		Trigger:        trigger,
+		Monitor:        monitor,
+	}
+
+	ready, err := registry.NewReadyChecker(registry.ReadinessPolicy{
+		MaxAge:        cfg.ReadyMaxAge,
+		MinEntries:    cfg.ReadyMinEntries,
+		RequireFetch:  cfg.ReadyRequireFetch,
+		AllowSnapshot: cfg.ReadyAllowSnapshot,
+	}, holder, monitor)
+	if err != nil {
+		return err
+	}
+
+	// The notifier subscribes to the holder before the updater starts,
+	// so not even the first update can slip by unnoticed.
+	var notifier *webhook.Notifier
+	if len(cfg.WebhookURLs) > 0 {
+		notifier, err = webhook.NewNotifier(webhook.Config{
+			Targets:    cfg.WebhookURLs,
+			Secret:     cfg.WebhookSecret,
+			QueueDir:   cfg.WebhookQueueDir,
+			MaxEntries: cfg.WebhookMaxEntries,
+		}, holder)
+		if err != nil {
+			return err
+		}
 	}
 
 	g, ctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
-		return registry.Start(ctx, updCfg, client, holder)
+		return registry.Start(ctx, updCfg, src, holder)
 	})
 
+	if notifier != nil {
+		g.Go(func() error {
+			return notifier.Run(ctx)
+		})
+	}
+
 	g.Go(func() error {
-		return grpc.RunGRPCServer(ctx, cfg.GRPCAddr, holder)
+		return grpc.RunGRPCServer(ctx, cfg.GRPCAddr, holder, monitor, ready, grpc.NewAdminServer(cfg.AdminToken, trigger))
 	})
 
 	g.Go(func() error {
-		return httpgw.RunHTTPGatewayServer(ctx, cfg.HTTPAddr, cfg.GRPCAddr, holder)
+		return httpgw.RunHTTPGatewayServer(ctx, cfg.HTTPAddr, cfg.GRPCAddr, ready)
 	})
 
 	if err := g.Wait(); err != nil {
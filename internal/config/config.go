@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,6 +13,43 @@ type Config struct {
 	GRPCAddr       string
 	RKNAPIBaseURL  string
 	UpdateInterval time.Duration
+
+	// Ordered registry sources (API base URLs, dump URLs, file:// paths),
+	// tried one after another. Defaults to RKNAPIBaseURL.
+	RegistrySources []string
+	// Allowed size gap between two sources before the smaller one is
+	// treated as truncated, 0 disables cross-checking.
+	SourceCrossCheck float64
+	// Time limit for fetching and parsing a dump.xml or dump.csv source.
+	DumpFetchTimeout time.Duration
+
+	// File the registry is persisted to after every update and restored
+	// from on startup, empty disables snapshots.
+	SnapshotPath string
+
+	// Sanity rules for fetched registries, see registry.GuardConfig.
+	// Fractions, 0 disables a rule.
+	GuardMaxDrop       float64
+	GuardMaxGrowth     float64
+	GuardMinEntries    int
+	GuardRequiredKinds []string
+
+	// Webhook targets notified of every accepted update, none disables
+	// webhooks. See webhook.Config.
+	WebhookURLs       []string
+	WebhookSecret     string
+	WebhookQueueDir   string
+	WebhookMaxEntries int
+
+	// Bearer token for the RegistryAdmin API, empty disables it.
+	AdminToken string
+
+	// Readiness rules, see registry.ReadinessPolicy. ReadyMaxAge is
+	// READY_MAX_AGE_INTERVALS times UpdateInterval, 0 disables it.
+	ReadyMaxAge        time.Duration
+	ReadyMinEntries    map[string]int
+	ReadyRequireFetch  bool
+	ReadyAllowSnapshot bool
 }
 
 func getenv(key, def string) string {
@@ -25,6 +64,8 @@ func Load() (Config, error) {
 		HTTPAddr:      getenv("HTTP_ADDR", ":80"),
 		GRPCAddr:      getenv("GRPC_ADDR", ":9090"),
 		RKNAPIBaseURL: getenv("RKN_API_BASE_URL", "https://reestr.rublacklist.net/api/v3"),
+		SnapshotPath:  os.Getenv("SNAPSHOT_PATH"),
+		AdminToken:    os.Getenv("ADMIN_TOKEN"),
 	}
 
 	intervalStr := getenv("UPDATE_INTERVAL", "6h")
@@ -43,6 +84,85 @@ func Load() (Config, error) {
 	if cfg.RKNAPIBaseURL == "" {
 		return Config{}, fmt.Errorf("RKN_API_BASE_URL must not be empty")
 	}
+	for _, spec := range strings.Split(getenv("REGISTRY_SOURCES", getenv("REGISTRY_SOURCE", cfg.RKNAPIBaseURL)), ",") {
+		if spec = strings.TrimSpace(spec); spec != "" {
+			cfg.RegistrySources = append(cfg.RegistrySources, spec)
+		}
+	}
+	if len(cfg.RegistrySources) == 0 {
+		return Config{}, fmt.Errorf("REGISTRY_SOURCES must not be empty")
+	}
+
+	crossStr := getenv("SOURCE_CROSS_CHECK", "0")
+	cross, err := strconv.ParseFloat(crossStr, 64)
+	if err != nil || cross < 0 || cross >= 1 {
+		return Config{}, fmt.Errorf("invalid SOURCE_CROSS_CHECK=%q: must be a fraction in [0, 1)", crossStr)
+	}
+	cfg.SourceCrossCheck = cross
+
+	dumpTimeoutStr := getenv("DUMP_FETCH_TIMEOUT", "30m")
+	if cfg.DumpFetchTimeout, err = time.ParseDuration(dumpTimeoutStr); err != nil || cfg.DumpFetchTimeout <= 0 {
+		return Config{}, fmt.Errorf("invalid DUMP_FETCH_TIMEOUT=%q: must be a positive duration", dumpTimeoutStr)
+	}
+
+	dropStr := getenv("GUARD_MAX_DROP", "0.5")
+	if cfg.GuardMaxDrop, err = strconv.ParseFloat(dropStr, 64); err != nil || cfg.GuardMaxDrop < 0 || cfg.GuardMaxDrop > 1 {
+		return Config{}, fmt.Errorf("invalid GUARD_MAX_DROP=%q: must be a fraction in [0, 1]", dropStr)
+	}
+	growthStr := getenv("GUARD_MAX_GROWTH", "0")
+	if cfg.GuardMaxGrowth, err = strconv.ParseFloat(growthStr, 64); err != nil || cfg.GuardMaxGrowth < 0 {
+		return Config{}, fmt.Errorf("invalid GUARD_MAX_GROWTH=%q: must be a non-negative fraction", growthStr)
+	}
+	minStr := getenv("GUARD_MIN_ENTRIES", "1")
+	if cfg.GuardMinEntries, err = strconv.Atoi(minStr); err != nil || cfg.GuardMinEntries < 0 {
+		return Config{}, fmt.Errorf("invalid GUARD_MIN_ENTRIES=%q: must be a non-negative integer", minStr)
+	}
+	for _, kind := range strings.Split(os.Getenv("GUARD_REQUIRE_KINDS"), ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			cfg.GuardRequiredKinds = append(cfg.GuardRequiredKinds, kind)
+		}
+	}
+
+	for _, u := range strings.Split(os.Getenv("WEBHOOK_URLS"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			cfg.WebhookURLs = append(cfg.WebhookURLs, u)
+		}
+	}
+	cfg.WebhookSecret = os.Getenv("WEBHOOK_SECRET")
+	cfg.WebhookQueueDir = os.Getenv("WEBHOOK_QUEUE_DIR")
+	maxEntriesStr := getenv("WEBHOOK_MAX_ENTRIES", "1000")
+	if cfg.WebhookMaxEntries, err = strconv.Atoi(maxEntriesStr); err != nil || cfg.WebhookMaxEntries <= 0 {
+		return Config{}, fmt.Errorf("invalid WEBHOOK_MAX_ENTRIES=%q: must be a positive integer", maxEntriesStr)
+	}
+
+	ageStr := getenv("READY_MAX_AGE_INTERVALS", "8")
+	ageIntervals, err := strconv.ParseFloat(ageStr, 64)
+	if err != nil || ageIntervals < 0 {
+		return Config{}, fmt.Errorf("invalid READY_MAX_AGE_INTERVALS=%q: must be a non-negative number", ageStr)
+	}
+	cfg.ReadyMaxAge = time.Duration(ageIntervals * float64(cfg.UpdateInterval))
+
+	cfg.ReadyMinEntries = map[string]int{}
+	for _, pair := range strings.Split(getenv("READY_MIN_ENTRIES", "domains=1"), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kind, nStr, ok := strings.Cut(pair, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(nStr))
+		if !ok || err != nil || n < 0 {
+			return Config{}, fmt.Errorf("invalid READY_MIN_ENTRIES entry %q: want kind=count", pair)
+		}
+		cfg.ReadyMinEntries[strings.TrimSpace(kind)] = n
+	}
+
+	requireStr := getenv("READY_REQUIRE_FETCH", "true")
+	if cfg.ReadyRequireFetch, err = strconv.ParseBool(requireStr); err != nil {
+		return Config{}, fmt.Errorf("invalid READY_REQUIRE_FETCH=%q: %w", requireStr, err)
+	}
+	snapshotStr := getenv("READY_ALLOW_SNAPSHOT", "true")
+	if cfg.ReadyAllowSnapshot, err = strconv.ParseBool(snapshotStr); err != nil {
+		return Config{}, fmt.Errorf("invalid READY_ALLOW_SNAPSHOT=%q: %w", snapshotStr, err)
+	}
 
 	return cfg, nil
 }
@@ -1,39 +1,150 @@
 package domain
 
 import (
-	"net"
-	"sort"
+	"net/netip"
 	"strings"
 )
 
-func IsBlocked(reg *Registry, n NormalizedURL) bool {
+// MatchKind tells which kind of registry rule blocked a URL.
+type MatchKind int
+
+const (
+	MatchNone         MatchKind = iota
+	MatchIP                     // IP literal host hit an address or subnet entry
+	MatchURL                    // http URL hit a URL entry
+	MatchHTTPSHost              // https URL hit the host of an https URL entry
+	MatchDomain                 // host is a domain entry itself
+	MatchParentDomain           // host is a subdomain of a domain entry
+)
+
+func (k MatchKind) String() string {
+	switch k {
+	case MatchIP:
+		return "ip"
+	case MatchURL:
+		return "url"
+	case MatchHTTPSHost:
+		return "https_host"
+	case MatchDomain:
+		return "domain"
+	case MatchParentDomain:
+		return "parent_domain"
+	default:
+		return "none"
+	}
+}
+
+// Match explains the result of IsBlocked.
+type Match struct {
+	Blocked bool
+	Kind    MatchKind
+	Rule    string // matched registry key, e.g. "blocked.com" for sub.blocked.com
+	Checked string // normalized URL that was checked, see URLKey
+}
+
+func IsBlocked(reg *Registry, n NormalizedURL) Match {
+	m := Match{Checked: URLKey(n)}
 	if reg == nil {
-		return false
+		return m
 	}
 
-	// 1) IP
-	if ip := net.ParseIP(n.Host); ip != nil {
-		if _, ok := reg.IPs[ip.String()]; ok {
-			return true
+	// 1) IP literal: longest matching address or subnet
+	if ip, err := netip.ParseAddr(n.Host); err == nil {
+		if hit, ok := matchIP(m, reg, ip); ok {
+			return hit
 		}
 	}
 
-	// 2) domains / subdomains
-	host := n.Host
-	hs := reg.DomainHashes
+	// 2) URL entries
+	switch n.Scheme {
+	case "http":
+		// Plain http: the operator sees the full request line, so match
+		// the exact URL, first with the query and then without it.
+		if n.Query != "" && containsKey(reg.URLHashes, reg.URLKeys, m.Checked) {
+			return m.hit(MatchURL, m.Checked)
+		}
+		bare := n
+		bare.Query = ""
+		if key := URLKey(bare); containsKey(reg.URLHashes, reg.URLKeys, key) {
+			return m.hit(MatchURL, key)
+		}
+	case "https":
+		// https: the path is hidden by TLS, so an https URL entry
+		// degrades to blocking the whole host.
+		if containsKey(reg.URLHostHashes, reg.URLHostKeys, n.Host) {
+			return m.hit(MatchHTTPSHost, n.Host)
+		}
+	}
+
+	// 3) domains / subdomains
+	return matchDomain(m, reg, n.Host)
+}
+
+// IsHostBlocked checks a host as returned by NormalizeHost. Without a URL
+// http URL entries cannot match; the host of an https URL entry is
+// blocked as a whole, so those do.
+func IsHostBlocked(reg *Registry, host string) Match {
+	m := Match{Checked: host}
+	if reg == nil {
+		return m
+	}
+
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if hit, ok := matchIP(m, reg, ip); ok {
+			return hit
+		}
+	}
+	if containsKey(reg.URLHostHashes, reg.URLHostKeys, host) {
+		return m.hit(MatchHTTPSHost, host)
+	}
+	return matchDomain(m, reg, host)
+}
+
+// IsIPBlocked checks an address against the address and subnet entries.
+func IsIPBlocked(reg *Registry, ip netip.Addr) Match {
+	ip = ip.Unmap().WithZone("")
+	m := Match{Checked: ip.String()}
+	if reg == nil {
+		return m
+	}
+
+	hit, _ := matchIP(m, reg, ip)
+	return hit
+}
+
+// matchIP looks up the longest address or subnet entry covering ip.
+func matchIP(m Match, reg *Registry, ip netip.Addr) (Match, bool) {
+	p, ok := reg.IPs.Lookup(ip)
+	if !ok {
+		return m, false
+	}
+	rule := p.String()
+	if p.IsSingleIP() {
+		rule = p.Addr().String()
+	}
+	return m.hit(MatchIP, rule), true
+}
+
+// matchDomain looks up host and then its parent domains.
+func matchDomain(m Match, reg *Registry, host string) Match {
+	kind := MatchDomain
 	for {
-		h := HashString64(host)
-		i := sort.Search(len(hs), func(i int) bool { return hs[i] >= h })
-		if i < len(hs) && hs[i] == h {
-			return true
+		if containsKey(reg.DomainHashes, reg.DomainKeys, host) {
+			return m.hit(kind, host)
 		}
 
 		j := strings.IndexByte(host, '.')
 		if j == -1 {
-			break
+			return m
 		}
 		host = host[j+1:]
+		kind = MatchParentDomain
 	}
+}
 
-	return false
+func (m Match) hit(kind MatchKind, rule string) Match {
+	m.Blocked = true
+	m.Kind = kind
+	m.Rule = rule
+	return m
 }
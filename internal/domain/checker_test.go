@@ -1,6 +1,10 @@
 package domain
 
-import "testing"
+import (
+	"net/netip"
+	"sort"
+	"testing"
+)
 
 func TestIsBlocked(t *testing.T) {
 	reg := &Registry{
@@ -10,7 +14,7 @@ func TestIsBlocked(t *testing.T) {
 		URLHashes: []uint64{
 			HashString64("http://blocked.com/path"),
 		},
-		IPs: make(map[string]struct{}),
+		IPs: NewIPSet(),
 	}
 
 	tests := []struct {
@@ -67,7 +71,7 @@ func TestIsBlocked(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := IsBlocked(reg, tt.n)
+			got := IsBlocked(reg, tt.n).Blocked
 			if got != tt.want {
 				t.Errorf("IsBlocked() = %v, want %v", got, tt.want)
 			}
@@ -79,29 +83,170 @@ func TestIsBlocked_IP(t *testing.T) {
 	reg := &Registry{
 		DomainHashes: nil,
 		URLHashes:    nil,
-		IPs:          map[string]struct{}{"203.0.113.5": {}},
+		IPs:          mustIPSet("203.0.113.5"),
 	}
 
 	n, err := Normalize("http://203.0.113.5/path")
 	if err != nil {
 		t.Fatalf("Normalize error: %v", err)
 	}
-	if !IsBlocked(reg, n) {
+	if !IsBlocked(reg, n).Blocked {
 		t.Errorf("expected IP to be blocked")
 	}
 }
 
+func TestIsBlocked_Subnet(t *testing.T) {
+	reg := &Registry{
+		IPs: mustIPSet("203.0.113.0/24", "2001:db8::/32"),
+	}
+
+	for raw, want := range map[string]bool{
+		"http://203.0.113.77/":     true,
+		"https://203.0.114.1/":     false,
+		"http://[2001:db8::5]/x":   true,
+		"http://[2001:db9::5]/x":   false,
+		"https://example.com/path": false,
+	} {
+		n, err := Normalize(raw)
+		if err != nil {
+			t.Fatalf("Normalize(%q) error: %v", raw, err)
+		}
+		if got := IsBlocked(reg, n).Blocked; got != want {
+			t.Errorf("IsBlocked(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestIsHostBlocked(t *testing.T) {
+	reg := &Registry{
+		DomainHashes:  []uint64{HashString64("blocked.com")},
+		URLHashes:     []uint64{HashString64("http://plain.com/page")},
+		URLHostHashes: []uint64{HashString64("tls.com")},
+		IPs:           mustIPSet("203.0.113.0/24"),
+	}
+
+	for host, want := range map[string]MatchKind{
+		"blocked.com":     MatchDomain,
+		"sub.blocked.com": MatchParentDomain,
+		"tls.com":         MatchHTTPSHost,
+		"plain.com":       MatchNone, // only a page of it is blocked
+		"203.0.113.9":     MatchIP,
+		"198.51.100.1":    MatchNone,
+	} {
+		m := IsHostBlocked(reg, host)
+		if m.Kind != want || m.Blocked != (want != MatchNone) || m.Checked != host {
+			t.Errorf("IsHostBlocked(%q) = %+v, want kind %s", host, m, want)
+		}
+	}
+}
+
+func TestIsIPBlocked(t *testing.T) {
+	reg := &Registry{
+		DomainHashes: []uint64{HashString64("203.0.113.5")},
+		IPs:          mustIPSet("2001:db8::/32", "198.51.100.7"),
+	}
+
+	for raw, want := range map[string]string{
+		"2001:db8::1":         "2001:db8::/32",
+		"::ffff:198.51.100.7": "198.51.100.7",
+		"fe80::1%eth0":        "",
+		"203.0.113.5":         "", // domain entries are not IP entries
+	} {
+		m := IsIPBlocked(reg, netip.MustParseAddr(raw))
+		if m.Rule != want || m.Blocked != (want != "") {
+			t.Errorf("IsIPBlocked(%q) = %+v, want rule %q", raw, m, want)
+		}
+	}
+}
+
+func TestIsBlocked_URLEntries(t *testing.T) {
+	reg := &Registry{
+		URLHashes: []uint64{
+			HashString64("http://example.com/blocked"),
+			HashString64("http://example.com/page?id=1"),
+		},
+		URLHostHashes: []uint64{
+			HashString64("secure.example.org"),
+		},
+		IPs: NewIPSet(),
+	}
+	sort.Slice(reg.URLHashes, func(i, j int) bool { return reg.URLHashes[i] < reg.URLHashes[j] })
+
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{raw: "http://example.com/blocked", want: true},
+		{raw: "http://Example.com:80/x/../blocked", want: true},
+		{raw: "http://example.com/blocked?utm=1", want: true},
+		{raw: "http://example.com/blocked#top", want: true},
+		{raw: "http://example.com/other", want: false},
+		{raw: "http://example.com/page?id=1", want: true},
+		{raw: "http://example.com/page?id=2", want: false},
+		{raw: "https://example.com/blocked", want: false},
+		{raw: "https://secure.example.org/any/path", want: true},
+		{raw: "https://sub.secure.example.org/", want: false},
+		{raw: "http://secure.example.org/any/path", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			n, err := Normalize(tt.raw)
+			if err != nil {
+				t.Fatalf("Normalize error: %v", err)
+			}
+			if got := IsBlocked(reg, n).Blocked; got != tt.want {
+				t.Errorf("IsBlocked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBlocked_Match(t *testing.T) {
+	reg := &Registry{
+		DomainHashes:  []uint64{HashString64("blocked.com")},
+		URLHashes:     []uint64{HashString64("http://example.com/page")},
+		URLHostHashes: []uint64{HashString64("secure.example.org")},
+		IPs:           mustIPSet("203.0.113.5", "198.51.100.0/24"),
+	}
+
+	tests := []struct {
+		raw  string
+		want Match
+	}{
+		{raw: "https://blocked.com/a", want: Match{Blocked: true, Kind: MatchDomain, Rule: "blocked.com", Checked: "https://blocked.com/a"}},
+		{raw: "https://a.sub.blocked.com", want: Match{Blocked: true, Kind: MatchParentDomain, Rule: "blocked.com", Checked: "https://a.sub.blocked.com/"}},
+		{raw: "http://example.com/page?x=1", want: Match{Blocked: true, Kind: MatchURL, Rule: "http://example.com/page", Checked: "http://example.com/page?x=1"}},
+		{raw: "https://secure.example.org/p", want: Match{Blocked: true, Kind: MatchHTTPSHost, Rule: "secure.example.org", Checked: "https://secure.example.org/p"}},
+		{raw: "http://203.0.113.5/", want: Match{Blocked: true, Kind: MatchIP, Rule: "203.0.113.5", Checked: "http://203.0.113.5/"}},
+		{raw: "http://198.51.100.9/", want: Match{Blocked: true, Kind: MatchIP, Rule: "198.51.100.0/24", Checked: "http://198.51.100.9/"}},
+		{raw: "https://other.com/", want: Match{Checked: "https://other.com/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			n, err := Normalize(tt.raw)
+			if err != nil {
+				t.Fatalf("Normalize error: %v", err)
+			}
+			if got := IsBlocked(reg, n); got != tt.want {
+				t.Errorf("IsBlocked() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 func BenchmarkIsBlocked_Hit(b *testing.B) {
 	reg := &Registry{
 		DomainHashes: []uint64{HashString64("blocked.com")},
 		URLHashes:    nil,
-		IPs:          make(map[string]struct{}),
+		IPs:          NewIPSet(),
 	}
 	n := NormalizedURL{Scheme: "https", Host: "blocked.com", Path: "/any"}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		if !IsBlocked(reg, n) {
+		if !IsBlocked(reg, n).Blocked {
 			b.Fatalf("expected blocked")
 		}
 	}
@@ -111,13 +256,13 @@ func BenchmarkIsBlocked_Miss(b *testing.B) {
 	reg := &Registry{
 		DomainHashes: []uint64{HashString64("blocked.com")},
 		URLHashes:    nil,
-		IPs:          make(map[string]struct{}),
+		IPs:          NewIPSet(),
 	}
 	n := NormalizedURL{Scheme: "https", Host: "other.com", Path: "/any"}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		if IsBlocked(reg, n) {
+		if IsBlocked(reg, n).Blocked {
 			b.Fatalf("expected not blocked")
 		}
 	}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+)
+
+// ContentFingerprint returns a digest of the registry entries: the sorted
+// hash slices and the IP set. Version, source and timestamps are left out,
+// so replicas serving the same data report the same fingerprint.
+func ContentFingerprint(r *Registry) string {
+	f := fingerprinter{h: sha256.New()}
+	f.hashes(r.DomainHashes)
+	f.hashes(r.URLHashes)
+	f.hashes(r.URLHostHashes)
+
+	f.uint64(uint64(r.IPs.Len()))
+	for p := range r.IPs.All() {
+		family := byte(6)
+		if p.Addr().Is4() {
+			family = 4
+		}
+		f.buf = append(f.buf, family, byte(p.Bits()))
+		f.buf = append(f.buf, p.Addr().AsSlice()...)
+		f.flush(false)
+	}
+	f.flush(true)
+
+	return hex.EncodeToString(f.h.Sum(nil)[:16])
+}
+
+// fingerprinter batches small writes to the digest.
+type fingerprinter struct {
+	h   hash.Hash
+	buf []byte
+}
+
+func (f *fingerprinter) hashes(hs []uint64) {
+	// The length keeps entries from sliding between kinds.
+	f.uint64(uint64(len(hs)))
+	for _, v := range hs {
+		f.uint64(v)
+	}
+}
+
+func (f *fingerprinter) uint64(v uint64) {
+	f.buf = binary.LittleEndian.AppendUint64(f.buf, v)
+	f.flush(false)
+}
+
+func (f *fingerprinter) flush(force bool) {
+	if force || len(f.buf) >= 4096 {
+		_, _ = f.h.Write(f.buf)
+		f.buf = f.buf[:0]
+	}
+}
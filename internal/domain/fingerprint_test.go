@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContentFingerprint(t *testing.T) {
+	a := &Registry{
+		DomainHashes: []uint64{1, 2},
+		URLHashes:    []uint64{3},
+		IPs:          mustIPSet("203.0.113.5", "198.51.100.0/24"),
+	}
+	// Same content, different metadata and insertion order.
+	b := &Registry{
+		DomainHashes: []uint64{1, 2},
+		URLHashes:    []uint64{3},
+		IPs:          mustIPSet("198.51.100.0/24", "203.0.113.5"),
+		Version:      7,
+		LastUpdated:  time.Now(),
+		Stats:        IngestStats{Source: "mirror"},
+	}
+	if ContentFingerprint(a) != ContentFingerprint(b) {
+		t.Fatal("fingerprint depends on metadata or insertion order")
+	}
+
+	// An entry moved to another kind is a different registry.
+	c := &Registry{
+		DomainHashes: []uint64{1},
+		URLHashes:    []uint64{2, 3},
+		IPs:          mustIPSet("203.0.113.5", "198.51.100.0/24"),
+	}
+	if ContentFingerprint(a) == ContentFingerprint(c) {
+		t.Fatal("fingerprint ignores entry kinds")
+	}
+
+	if ContentFingerprint(&Registry{}) == ContentFingerprint(&Registry{IPs: mustIPSet("203.0.113.5")}) {
+		t.Fatal("fingerprint ignores IPs")
+	}
+}
@@ -0,0 +1,232 @@
+package domain
+
+import (
+	"iter"
+	"math/bits"
+	"net/netip"
+)
+
+// IPSet is a set of IPv4/IPv6 addresses and subnets.
+// It is a path-compressed binary trie (one per address family), so a lookup
+// costs at most one node per distinct prefix length on the way down and
+// returns the longest matching prefix.
+//
+// The zero value is an empty set. A nil *IPSet is also treated as empty by
+// all read methods. IPSet is not safe for concurrent writes; registries are
+// built once and then only read.
+type IPSet struct {
+	v4  *ipNode
+	v6  *ipNode
+	len int
+}
+
+type ipNode struct {
+	key      uint128 // prefix bits, everything after plen is zero
+	plen     int
+	terminal bool // node is a stored prefix, not only a branching point
+	child    [2]*ipNode
+}
+
+// uint128 holds address bits MSB-first. IPv4 occupies the top 32 bits of hi.
+type uint128 struct {
+	hi, lo uint64
+}
+
+func NewIPSet() *IPSet {
+	return &IPSet{}
+}
+
+// Len returns the number of stored addresses and subnets.
+func (s *IPSet) Len() int {
+	if s == nil {
+		return 0
+	}
+	return s.len
+}
+
+// Insert adds a subnet to the set. Single addresses are stored as /32 or
+// /128 prefixes. IPv4-mapped IPv6 prefixes are stored as IPv4.
+func (s *IPSet) Insert(p netip.Prefix) {
+	if !p.IsValid() {
+		return
+	}
+	p = unmapPrefix(p).Masked()
+
+	root := &s.v6
+	if p.Addr().Is4() {
+		root = &s.v4
+	}
+	if insertNode(root, addrBits(p.Addr()), p.Bits()) {
+		s.len++
+	}
+}
+
+// InsertAddr adds a single address to the set.
+func (s *IPSet) InsertAddr(a netip.Addr) {
+	a = a.Unmap()
+	s.Insert(netip.PrefixFrom(a, a.BitLen()))
+}
+
+// Contains reports whether a is covered by any address or subnet in the set.
+func (s *IPSet) Contains(a netip.Addr) bool {
+	_, ok := s.Lookup(a)
+	return ok
+}
+
+// Lookup returns the longest stored prefix that covers a.
+func (s *IPSet) Lookup(a netip.Addr) (netip.Prefix, bool) {
+	if s == nil || !a.IsValid() {
+		return netip.Prefix{}, false
+	}
+	a = a.WithZone("").Unmap()
+
+	n := s.v6
+	if a.Is4() {
+		n = s.v4
+	}
+	key := addrBits(a)
+	maxBits := a.BitLen()
+
+	var best *ipNode
+	for n != nil {
+		if commonPrefixLen(n.key, key, n.plen) < n.plen {
+			break
+		}
+		if n.terminal {
+			best = n
+		}
+		if n.plen >= maxBits {
+			break
+		}
+		n = n.child[key.bit(n.plen)]
+	}
+
+	if best == nil {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(bitsAddr(best.key, a.Is4()), best.plen), true
+}
+
+// All yields every stored prefix, IPv4 first, each family in address order
+// with a covering subnet before the prefixes inside it.
+func (s *IPSet) All() iter.Seq[netip.Prefix] {
+	return func(yield func(netip.Prefix) bool) {
+		if s == nil {
+			return
+		}
+		_ = walkNodes(s.v4, true, yield) && walkNodes(s.v6, false, yield)
+	}
+}
+
+func walkNodes(n *ipNode, is4 bool, yield func(netip.Prefix) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.terminal && !yield(netip.PrefixFrom(bitsAddr(n.key, is4), n.plen)) {
+		return false
+	}
+	return walkNodes(n.child[0], is4, yield) && walkNodes(n.child[1], is4, yield)
+}
+
+// insertNode inserts key/plen below *np and reports whether a new prefix
+// was added (false if it was already present).
+func insertNode(np **ipNode, key uint128, plen int) bool {
+	for {
+		n := *np
+		if n == nil {
+			*np = &ipNode{key: key, plen: plen, terminal: true}
+			return true
+		}
+
+		common := commonPrefixLen(n.key, key, min(n.plen, plen))
+		if common == n.plen {
+			if plen == n.plen {
+				added := !n.terminal
+				n.terminal = true
+				return added
+			}
+			np = &n.child[key.bit(n.plen)]
+			continue
+		}
+
+		// Keys diverge inside n's prefix: split it with a branching node.
+		mid := &ipNode{key: key.mask(common), plen: common}
+		mid.child[n.key.bit(common)] = n
+		if plen == common {
+			mid.terminal = true
+		} else {
+			mid.child[key.bit(common)] = &ipNode{key: key, plen: plen, terminal: true}
+		}
+		*np = mid
+		return true
+	}
+}
+
+func unmapPrefix(p netip.Prefix) netip.Prefix {
+	if a := p.Addr(); a.Is4In6() && p.Bits() >= 96 {
+		return netip.PrefixFrom(a.Unmap(), p.Bits()-96)
+	}
+	return p
+}
+
+func addrBits(a netip.Addr) uint128 {
+	if a.Is4() {
+		b := a.As4()
+		return uint128{hi: uint64(b[0])<<56 | uint64(b[1])<<48 | uint64(b[2])<<40 | uint64(b[3])<<32}
+	}
+	b := a.As16()
+	var u uint128
+	for i := 0; i < 8; i++ {
+		u.hi = u.hi<<8 | uint64(b[i])
+		u.lo = u.lo<<8 | uint64(b[i+8])
+	}
+	return u
+}
+
+func bitsAddr(u uint128, is4 bool) netip.Addr {
+	if is4 {
+		return netip.AddrFrom4([4]byte{byte(u.hi >> 56), byte(u.hi >> 48), byte(u.hi >> 40), byte(u.hi >> 32)})
+	}
+	var b [16]byte
+	for i := 0; i < 8; i++ {
+		b[i] = byte(u.hi >> (56 - 8*i))
+		b[i+8] = byte(u.lo >> (56 - 8*i))
+	}
+	return netip.AddrFrom16(b)
+}
+
+// bit returns the i-th bit counting from the most significant one.
+func (u uint128) bit(i int) int {
+	if i < 64 {
+		return int(u.hi >> (63 - i) & 1)
+	}
+	return int(u.lo >> (127 - i) & 1)
+}
+
+// mask keeps the first n bits and zeroes the rest.
+func (u uint128) mask(n int) uint128 {
+	switch {
+	case n <= 0:
+		return uint128{}
+	case n < 64:
+		return uint128{hi: u.hi &^ (^uint64(0) >> n)}
+	case n < 128:
+		return uint128{hi: u.hi, lo: u.lo &^ (^uint64(0) >> (n - 64))}
+	default:
+		return u
+	}
+}
+
+// commonPrefixLen returns the number of leading bits a and b share, capped at max.
+func commonPrefixLen(a, b uint128, max int) int {
+	var n int
+	if x := a.hi ^ b.hi; x != 0 {
+		n = bits.LeadingZeros64(x)
+	} else {
+		n = 64 + bits.LeadingZeros64(a.lo^b.lo)
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
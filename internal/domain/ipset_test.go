@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// mustIPSet builds a set from addresses ("1.2.3.4") and subnets ("1.2.3.0/24").
+func mustIPSet(entries ...string) *IPSet {
+	s := NewIPSet()
+	for _, e := range entries {
+		if strings.Contains(e, "/") {
+			s.Insert(netip.MustParsePrefix(e))
+		} else {
+			s.InsertAddr(netip.MustParseAddr(e))
+		}
+	}
+	return s
+}
+
+func TestIPSet_Lookup(t *testing.T) {
+	s := mustIPSet(
+		"203.0.113.5",
+		"198.51.100.0/24",
+		"198.51.100.128/25",
+		"10.0.0.0/8",
+		"2001:db8::/32",
+		"2001:db8:1::/48",
+		"2001:db8:2::1",
+	)
+
+	tests := []struct {
+		addr string
+		want string // expected matched prefix, empty if no match
+	}{
+		{addr: "203.0.113.5", want: "203.0.113.5/32"},
+		{addr: "203.0.113.6", want: ""},
+		{addr: "198.51.100.1", want: "198.51.100.0/24"},
+		{addr: "198.51.100.200", want: "198.51.100.128/25"},
+		{addr: "198.51.101.1", want: ""},
+		{addr: "10.255.255.255", want: "10.0.0.0/8"},
+		{addr: "11.0.0.0", want: ""},
+		{addr: "::ffff:10.1.2.3", want: "10.0.0.0/8"},
+		{addr: "2001:db8:ffff::1", want: "2001:db8::/32"},
+		{addr: "2001:db8:1:2::1", want: "2001:db8:1::/48"},
+		{addr: "2001:db8:2::1", want: "2001:db8:2::1/128"},
+		{addr: "2001:db9::1", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			got, ok := s.Lookup(netip.MustParseAddr(tt.addr))
+			if tt.want == "" {
+				if ok {
+					t.Fatalf("Lookup() = %s, want no match", got)
+				}
+				return
+			}
+			if !ok || got.String() != tt.want {
+				t.Fatalf("Lookup() = %s, %v, want %s", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPSet_LenAndDuplicates(t *testing.T) {
+	s := mustIPSet("192.0.2.0/24", "192.0.2.1", "192.0.2.77/24", "192.0.2.1", "::ffff:192.0.2.1")
+	if s.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", s.Len())
+	}
+
+	var empty *IPSet
+	if empty.Len() != 0 || empty.Contains(netip.MustParseAddr("192.0.2.1")) {
+		t.Fatal("nil set must be empty")
+	}
+}
+
+func TestIPSet_DefaultRoute(t *testing.T) {
+	s := mustIPSet("0.0.0.0/0")
+	if got, ok := s.Lookup(netip.MustParseAddr("8.8.8.8")); !ok || got.String() != "0.0.0.0/0" {
+		t.Fatalf("Lookup() = %s, %v, want 0.0.0.0/0", got, ok)
+	}
+	if s.Contains(netip.MustParseAddr("2001:db8::1")) {
+		t.Fatal("IPv4 default route must not cover IPv6")
+	}
+}
+
+func TestIPSet_All(t *testing.T) {
+	s := mustIPSet("2001:db8::/32", "198.51.100.128/25", "10.0.0.0/8", "198.51.100.0/24", "10.1.2.3")
+
+	var got []string
+	for p := range s.All() {
+		got = append(got, p.String())
+	}
+	want := "10.0.0.0/8 10.1.2.3/32 198.51.100.0/24 198.51.100.128/25 2001:db8::/32"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("All() = %v, want %s", got, want)
+	}
+}
+
+func BenchmarkIPSet_Lookup(b *testing.B) {
+	s := NewIPSet()
+	for i := 0; i < 100_000; i++ {
+		s.InsertAddr(netip.AddrFrom4([4]byte{10, byte(i >> 16), byte(i >> 8), byte(i)}))
+	}
+	s.Insert(netip.MustParsePrefix("198.51.100.0/24"))
+	addr := netip.MustParseAddr("198.51.100.77")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !s.Contains(addr) {
+			b.Fatalf("expected match")
+		}
+	}
+}
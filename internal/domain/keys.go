@@ -0,0 +1,66 @@
+package domain
+
+import "sort"
+
+// KeyTable keeps the canonical strings behind a sorted hash slice:
+// entry i is the string that produced hashes[i]. All strings are packed
+// into a single arena, so a million domains cost two allocations instead
+// of a million.
+type KeyTable struct {
+	data []byte
+	offs []uint32 // len(offs) == Len()+1, entry i is data[offs[i]:offs[i+1]]
+}
+
+// NewKeyTable packs keys into an arena, preserving their order.
+func NewKeyTable(keys []string) *KeyTable {
+	size := 0
+	for _, k := range keys {
+		size += len(k)
+	}
+
+	t := &KeyTable{
+		data: make([]byte, 0, size),
+		offs: make([]uint32, 0, len(keys)+1),
+	}
+	t.offs = append(t.offs, 0)
+	for _, k := range keys {
+		t.data = append(t.data, k...)
+		t.offs = append(t.offs, uint32(len(t.data)))
+	}
+	return t
+}
+
+// Len returns the number of keys. A nil table is empty.
+func (t *KeyTable) Len() int {
+	if t == nil || len(t.offs) == 0 {
+		return 0
+	}
+	return len(t.offs) - 1
+}
+
+// At returns the i-th key.
+func (t *KeyTable) At(i int) string {
+	return string(t.data[t.offs[i]:t.offs[i+1]])
+}
+
+// equal compares the i-th key with s without allocating.
+func (t *KeyTable) equal(i int, s string) bool {
+	return string(t.data[t.offs[i]:t.offs[i+1]]) == s
+}
+
+// containsKey reports whether s is present in the sorted hash slice hs.
+// When keys is aligned with hs (verified mode) a hash hit is confirmed by
+// comparing the original string, so 64-bit collisions cannot produce a
+// false positive. Otherwise the hash hit alone is trusted.
+func containsKey(hs []uint64, keys *KeyTable, s string) bool {
+	h := HashString64(s)
+	verified := keys.Len() == len(hs) && len(hs) > 0
+
+	i := sort.Search(len(hs), func(i int) bool { return hs[i] >= h })
+	for ; i < len(hs) && hs[i] == h; i++ {
+		if !verified || keys.equal(i, s) {
+			return true
+		}
+	}
+	return false
+}
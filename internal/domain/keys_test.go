@@ -0,0 +1,56 @@
+package domain
+
+import "testing"
+
+func TestKeyTable(t *testing.T) {
+	keys := []string{"a.com", "", "пример.рф"}
+	kt := NewKeyTable(keys)
+
+	if kt.Len() != len(keys) {
+		t.Fatalf("Len() = %d, want %d", kt.Len(), len(keys))
+	}
+	for i, k := range keys {
+		if got := kt.At(i); got != k {
+			t.Errorf("At(%d) = %q, want %q", i, got, k)
+		}
+	}
+
+	var empty *KeyTable
+	if empty.Len() != 0 {
+		t.Fatalf("nil table Len() = %d, want 0", empty.Len())
+	}
+}
+
+func TestIsBlocked_VerifiedModeRejectsCollision(t *testing.T) {
+	// Simulate a collision: the hash of "blocked.com" is present, but it
+	// was produced by a different string.
+	reg := &Registry{
+		DomainHashes: []uint64{HashString64("blocked.com")},
+		DomainKeys:   NewKeyTable([]string{"colliding.org"}),
+	}
+
+	n := NormalizedURL{Scheme: "https", Host: "blocked.com", Path: "/"}
+	if IsBlocked(reg, n).Blocked {
+		t.Fatal("verified mode must reject a hash hit with a different key")
+	}
+
+	// Same registry without keys trusts the hash.
+	reg.DomainKeys = nil
+	if !IsBlocked(reg, n).Blocked {
+		t.Fatal("hash-only mode must trust the hash hit")
+	}
+}
+
+func TestIsBlocked_VerifiedModeSameHashRun(t *testing.T) {
+	// Two distinct keys sharing one hash sit next to each other.
+	h := HashString64("blocked.com")
+	reg := &Registry{
+		DomainHashes: []uint64{h, h},
+		DomainKeys:   NewKeyTable([]string{"colliding.org", "blocked.com"}),
+	}
+
+	n := NormalizedURL{Scheme: "https", Host: "blocked.com", Path: "/"}
+	if !IsBlocked(reg, n).Blocked {
+		t.Fatal("expected blocked.com to be found after a colliding key")
+	}
+}
@@ -3,14 +3,60 @@ package domain
 import "time"
 
 // Registry — in memory representation of blocking list.
-// NOTE: domain and URL hashes are 64-bit and collisions are theoretically possible,
-// but considered acceptable for this task. If false positives become critical,
-// consider storing original strings or using a stronger scheme (e.g. hash + length).
+// Domain and URL entries are looked up by 64-bit hash. Collisions are possible,
+// so each hash slice may be paired with a KeyTable holding the original strings
+// in the same order (verified mode): a hash hit is then confirmed by string
+// comparison. Without keys a hash hit alone is trusted.
 type Registry struct {
-	DomainHashes []uint64 // Sorted hash domains
-	URLHashes    []uint64
-	IPs          map[string]struct{}
-	LastUpdated  time.Time
+	DomainHashes  []uint64 // Sorted hash domains
+	URLHashes     []uint64 // Sorted hash of http URL entries, see URLKey
+	URLHostHashes []uint64 // Sorted hash of hosts from https URL entries
+
+	DomainKeys  *KeyTable // Optional, aligned with DomainHashes
+	URLKeys     *KeyTable // Optional, aligned with URLHashes
+	URLHostKeys *KeyTable // Optional, aligned with URLHostHashes
+
+	IPs         *IPSet // Single addresses and subnets, nil means empty
+	LastUpdated time.Time
+	Version     uint64 // Assigned by registry.Holder, grows with every content change
+	Fingerprint string // Set by registry.Holder, see ContentFingerprint
+
+	Stats IngestStats // How the registry was fetched and built, informational only
+}
+
+// SkipStats counts entries of one kind dropped while building a registry.
+type SkipStats struct {
+	Empty      int // blank entries
+	Underscore int // domains with '_' in name, garbage like "bad_domain"
+	Invalid    int // entries rejected by the normalizer
+}
+
+// Total returns the number of skipped entries.
+func (s SkipStats) Total() int {
+	return s.Empty + s.Underscore + s.Invalid
+}
+
+// IngestStats describes how a registry was fetched and built.
+type IngestStats struct {
+	Source string // location the registry was read from
+
+	Domains SkipStats
+	URLs    SkipStats
+	IPs     SkipStats
+
+	// Malformed counts source records that could not be parsed at all.
+	Malformed int
+
+	// Collisions counts distinct keys of the same kind sharing a 64-bit hash.
+	// They are kept apart by string comparison, see KeyTable.
+	Collisions int
+
+	// Bytes read from the source as transferred and after decompression.
+	// They are equal for uncompressed sources.
+	CompressedBytes   int64
+	DecompressedBytes int64
+
+	Samples []string // first few accepted domains, handy for eyeballing
 }
 
 // NormalizedURL — result of normalize
@@ -18,4 +64,5 @@ type NormalizedURL struct {
 	Scheme string // "http" or "https"
 	Host   string // example.com
 	Path   string // normalize path
+	Query  string // raw query without "?", empty if absent
 }
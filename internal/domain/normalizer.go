@@ -3,6 +3,7 @@ package domain
 import (
 	"fmt"
 	"net"
+	"net/netip"
 	"path"
 	"strings"
 	"unicode/utf8"
@@ -40,6 +41,18 @@ func Normalize(raw string) (NormalizedURL, error) {
 		return NormalizedURL{}, fmt.Errorf("unsupported scheme: %s", schemePart)
 	}
 
+	// Fragments never reach the server, drop them right away.
+	if hash := strings.IndexByte(rest, '#'); hash != -1 {
+		rest = rest[:hash]
+	}
+
+	// Split off the query so that path cleaning never touches it.
+	q := ""
+	if qm := strings.IndexByte(rest, '?'); qm != -1 {
+		q = rest[qm+1:]
+		rest = rest[:qm]
+	}
+
 	// Split host[:port] and path.
 	hostport := rest
 	p := ""
@@ -59,15 +72,67 @@ func Normalize(raw string) (NormalizedURL, error) {
 		Scheme: scheme,
 		Host:   host,
 		Path:   p,
+		Query:  q,
 	}, nil
 }
 
+// NormalizeLenient is Normalize for input the way users paste it: without
+// a scheme, e.g. "example.com", "example.com:8080" or "example.com/path",
+// it is taken as an http URL.
+func NormalizeLenient(raw string) (NormalizedURL, error) {
+	raw = strings.TrimSpace(raw)
+	// A "://" after the first '/', '?' or '#' belongs to the path or the
+	// query, e.g. "example.com/go?to=https://other.com".
+	if i := strings.Index(raw, "://"); raw != "" && (i <= 0 || strings.ContainsAny(raw[:i], "/?#")) {
+		raw = "http://" + strings.TrimPrefix(raw, "//")
+	}
+	return Normalize(raw)
+}
+
+// URLKey returns the canonical string form of a normalized URL:
+// scheme://host/path, with "?query" appended when the query is not empty.
+// Registry URL entries are hashed in this form.
+func URLKey(n NormalizedURL) string {
+	if n.Query == "" {
+		return n.Scheme + "://" + n.Host + n.Path
+	}
+	return n.Scheme + "://" + n.Host + n.Path + "?" + n.Query
+}
+
 // NormalizeHost normalizes a raw host/domain string (no scheme, no path).
 // Used in places where only the hostname matters (e.g. registry loading).
 func NormalizeHost(raw string) (string, error) {
 	return normalizeHost(raw)
 }
 
+// ParseIPOrSubnet validates a registry IP entry and returns it in canonical
+// form: "203.0.113.5" becomes 203.0.113.5/32, "203.0.113.7/24" is masked
+// to 203.0.113.0/24. Parsing follows net.ParseIP and net.ParseCIDR rules.
+func ParseIPOrSubnet(raw string) (netip.Prefix, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return netip.Prefix{}, fmt.Errorf("empty ip")
+	}
+
+	if strings.IndexByte(raw, '/') != -1 {
+		_, ipnet, err := net.ParseCIDR(raw)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid subnet %q", raw)
+		}
+		addr, _ := netip.AddrFromSlice(ipnet.IP)
+		ones, _ := ipnet.Mask.Size()
+		return unmapPrefix(netip.PrefixFrom(addr, ones)), nil
+	}
+
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return netip.Prefix{}, fmt.Errorf("invalid ip %q", raw)
+	}
+	addr, _ := netip.AddrFromSlice(ip)
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
 func normalizeHost(hostport string) (string, error) {
 	hostport = strings.TrimSpace(hostport)
 	if hostport == "" {
@@ -111,6 +111,53 @@ func TestNormalize_MoreCases(t *testing.T) {
 	}
 }
 
+func TestNormalize_QueryAndFragment(t *testing.T) {
+	n, err := Normalize("http://example.com/a/./b.html?x=1.2&y=../z#frag")
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if n.Path != "/a/b.html" {
+		t.Errorf("Path = %q, want %q", n.Path, "/a/b.html")
+	}
+	if n.Query != "x=1.2&y=../z" {
+		t.Errorf("Query = %q, want %q", n.Query, "x=1.2&y=../z")
+	}
+	if got, want := URLKey(n), "http://example.com/a/b.html?x=1.2&y=../z"; got != want {
+		t.Errorf("URLKey = %q, want %q", got, want)
+	}
+}
+
+func TestParseIPOrSubnet(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "203.0.113.5", want: "203.0.113.5/32"},
+		{raw: " 203.0.113.7/24 ", want: "203.0.113.0/24"},
+		{raw: "::ffff:203.0.113.5", want: "203.0.113.5/32"},
+		{raw: "::ffff:203.0.113.0/120", want: "203.0.113.0/24"},
+		{raw: "2001:DB8::1", want: "2001:db8::1/128"},
+		{raw: "2001:db8::1/32", want: "2001:db8::/32"},
+		{raw: "", wantErr: true},
+		{raw: "999.1.1.1", wantErr: true},
+		{raw: "203.0.113.0/33", wantErr: true},
+		{raw: "example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseIPOrSubnet(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseIPOrSubnet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Fatalf("ParseIPOrSubnet() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestNormalize_InvalidURLs(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -131,13 +178,41 @@ func TestNormalize_InvalidURLs(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeLenient(t *testing.T) {
+	for raw, want := range map[string]string{
+		"Example.com":                         "http://example.com/",
+		"example.com:8080/a/../b":             "http://example.com/b",
+		"//example.com/x":                     "http://example.com/x",
+		"203.0.113.5":                         "http://203.0.113.5/",
+		"[2001:db8::1]:443":                   "http://2001:db8::1/",
+		"https://Example.com/x":               "https://example.com/x",
+		"example.com/go?to=https://other.com": "http://example.com/go?to=https://other.com",
+	} {
+		n, err := NormalizeLenient(raw)
+		if err != nil {
+			t.Errorf("NormalizeLenient(%q) error: %v", raw, err)
+			continue
+		}
+		if got := URLKey(n); got != want {
+			t.Errorf("NormalizeLenient(%q) = %q, want %q", raw, got, want)
+		}
+	}
+
+	for _, raw := range []string{"", "ftp://example.com", "://example.com"} {
+		if _, err := NormalizeLenient(raw); err == nil {
+			t.Errorf("NormalizeLenient(%q) succeeded, want error", raw)
+		}
+	}
+}
+
 func TestIsBlocked_DomainAndSubdomain(t *testing.T) {
 	reg := &Registry{
 		DomainHashes: []uint64{
 			HashString64("example.com"),
 		},
 		URLHashes: nil,
-		IPs:       make(map[string]struct{}),
+		IPs:       NewIPSet(),
 	}
 
 	blockedURL := NormalizedURL{
@@ -152,10 +227,10 @@ func TestIsBlocked_DomainAndSubdomain(t *testing.T) {
 		Path:   "/",
 	}
 
-	if !IsBlocked(reg, blockedURL) {
+	if !IsBlocked(reg, blockedURL).Blocked {
 		t.Errorf("expected sub.example.com to be blocked")
 	}
-	if IsBlocked(reg, notBlockedURL) {
+	if IsBlocked(reg, notBlockedURL).Blocked {
 		t.Errorf("expected anotherexample.com not to be blocked")
 	}
 }
@@ -164,14 +239,14 @@ func TestIsBlocked_IP_FromNormalizerFile(t *testing.T) {
 	reg := &Registry{
 		DomainHashes: nil,
 		URLHashes:    nil,
-		IPs:          map[string]struct{}{"203.0.113.5": {}},
+		IPs:          mustIPSet("203.0.113.5"),
 	}
 
 	n, err := Normalize("http://203.0.113.5/path")
 	if err != nil {
 		t.Fatalf("Normalize error: %v", err)
 	}
-	if !IsBlocked(reg, n) {
+	if !IsBlocked(reg, n).Blocked {
 		t.Errorf("expected IP to be blocked")
 	}
 }
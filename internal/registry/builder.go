@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"evil-rkn/internal/domain"
+)
+
+// builder accumulates raw registry entries, normalizes them and
+// produces an immutable *domain.Registry.
+type builder struct {
+	domains  []string
+	urls     []string
+	urlHosts []string
+	ips      *domain.IPSet
+
+	hash  func(string) uint64
+	stats domain.IngestStats
+}
+
+func newBuilder(source string) *builder {
+	return &builder{
+		domains: make([]string, 0, 1_000_000),
+		ips:     domain.NewIPSet(),
+		hash:    domain.HashString64,
+		stats:   domain.IngestStats{Source: source},
+	}
+}
+
+// AddDomain adds a domain entry. Subdomains of the domain are blocked too.
+func (b *builder) AddDomain(raw string) {
+	raw = strings.TrimSpace(strings.ToLower(raw))
+	if raw == "" {
+		// Completely empty entry — just ignore it.
+		b.stats.Domains.Empty++
+		return
+	}
+	if strings.Contains(raw, "_") {
+		// RKN occasionally returns garbage like "bad_domain".
+		b.stats.Domains.Underscore++
+		return
+	}
+
+	host, err := domain.NormalizeHost(raw)
+	if err != nil {
+		b.stats.Domains.Invalid++
+		return
+	}
+
+	if len(b.stats.Samples) < 5 {
+		b.stats.Samples = append(b.stats.Samples, host)
+	}
+
+	b.domains = append(b.domains, host)
+}
+
+// AddURL adds a full URL entry.
+// http entries are matched by URL, https entries block the whole host
+// because the operator cannot see the path inside TLS.
+func (b *builder) AddURL(raw string) {
+	if strings.TrimSpace(raw) == "" {
+		b.stats.URLs.Empty++
+		return
+	}
+
+	n, err := domain.Normalize(raw)
+	if err != nil {
+		b.stats.URLs.Invalid++
+		return
+	}
+
+	switch n.Scheme {
+	case "https":
+		b.urlHosts = append(b.urlHosts, n.Host)
+	default:
+		b.urls = append(b.urls, domain.URLKey(n))
+	}
+}
+
+// AddIP adds a single address ("203.0.113.5") or a subnet ("203.0.113.0/24").
+func (b *builder) AddIP(raw string) {
+	if strings.TrimSpace(raw) == "" {
+		b.stats.IPs.Empty++
+		return
+	}
+
+	p, err := domain.ParseIPOrSubnet(raw)
+	if err != nil {
+		b.stats.IPs.Invalid++
+		return
+	}
+
+	b.ips.Insert(p)
+}
+
+// Stats returns counters collected so far.
+func (b *builder) Stats() domain.IngestStats {
+	return b.stats
+}
+
+// Build sorts and deduplicates collected entries and returns the registry
+// in verified mode: every hash slice comes with its original strings.
+func (b *builder) Build() *domain.Registry {
+	reg := &domain.Registry{IPs: b.ips}
+	reg.DomainHashes, reg.DomainKeys = b.buildKeys("domain", b.domains)
+	reg.URLHashes, reg.URLKeys = b.buildKeys("url", b.urls)
+	reg.URLHostHashes, reg.URLHostKeys = b.buildKeys("https host", b.urlHosts)
+	reg.LastUpdated = time.Now().UTC()
+	reg.Stats = b.stats
+	return reg
+}
+
+// buildKeys hashes keys, sorts them by (hash, key), drops duplicates and
+// counts distinct keys that share a hash.
+func (b *builder) buildKeys(kind string, keys []string) ([]uint64, *domain.KeyTable) {
+	type entry struct {
+		h uint64
+		s string
+	}
+
+	entries := make([]entry, len(keys))
+	for i, k := range keys {
+		entries[i] = entry{h: b.hash(k), s: k}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].h != entries[j].h {
+			return entries[i].h < entries[j].h
+		}
+		return entries[i].s < entries[j].s
+	})
+
+	hashes := make([]uint64, 0, len(entries))
+	uniq := make([]string, 0, len(entries))
+	for i, e := range entries {
+		if i > 0 && e.s == entries[i-1].s {
+			continue
+		}
+		if len(hashes) > 0 && hashes[len(hashes)-1] == e.h {
+			b.stats.Collisions++
+			log.Printf("registry: hash collision between %s entries %q and %q", kind, uniq[len(uniq)-1], e.s)
+		}
+		hashes = append(hashes, e.h)
+		uniq = append(uniq, e.s)
+	}
+
+	return hashes, domain.NewKeyTable(uniq)
+}
+
+// logBuild reports per-kind skip counters and registry size.
+func logBuild(prefix string, reg *domain.Registry) {
+	st := reg.Stats
+	log.Printf("%s: skipped %d domains with '_' in name", prefix, st.Domains.Underscore)
+	log.Printf("%s: skipped %d domains due to normalize errors", prefix, st.Domains.Invalid)
+	log.Printf("%s: skipped %d empty domains", prefix, st.Domains.Empty)
+	log.Printf("%s: skipped %d urls (%d empty, %d invalid)", prefix, st.URLs.Total(), st.URLs.Empty, st.URLs.Invalid)
+	log.Printf("%s: skipped %d ips (%d empty, %d invalid)", prefix, st.IPs.Total(), st.IPs.Empty, st.IPs.Invalid)
+	log.Printf("%s: registry built: %d domains, %d urls, %d https hosts, %d ips",
+		prefix, len(reg.DomainHashes), len(reg.URLHashes), len(reg.URLHostHashes), reg.IPs.Len())
+	if st.CompressedBytes != st.DecompressedBytes {
+		log.Printf("%s: downloaded %d bytes, %d after decompression", prefix, st.CompressedBytes, st.DecompressedBytes)
+	}
+	if st.Malformed > 0 {
+		log.Printf("%s: skipped %d malformed records", prefix, st.Malformed)
+	}
+	if st.Collisions > 0 {
+		log.Printf("%s: %d hash collisions resolved by string comparison", prefix, st.Collisions)
+	}
+	for i, s := range st.Samples {
+		log.Printf("%s: sample domain[%d]=%s", prefix, i, s)
+	}
+}
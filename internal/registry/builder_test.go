@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"testing"
+
+	"evil-rkn/internal/domain"
+)
+
+func TestBuilder_Stats(t *testing.T) {
+	b := newBuilder("test")
+
+	for _, d := range []string{"example.com", "", "  ", "bad_domain.com", "Example.COM"} {
+		b.AddDomain(d)
+	}
+	for _, u := range []string{"http://example.com/a", "", "ftp://example.com/a"} {
+		b.AddURL(u)
+	}
+	for _, ip := range []string{"203.0.113.5", "203.0.113.0/24", "", "1.2.3", "1.2.3.4/40"} {
+		b.AddIP(ip)
+	}
+
+	st := b.Stats()
+	want := domain.IngestStats{
+		Domains: domain.SkipStats{Empty: 2, Underscore: 1},
+		URLs:    domain.SkipStats{Empty: 1, Invalid: 1},
+		IPs:     domain.SkipStats{Empty: 1, Invalid: 2},
+	}
+	if st.Domains != want.Domains || st.URLs != want.URLs || st.IPs != want.IPs {
+		t.Fatalf("stats = %+v, want %+v", st, want)
+	}
+
+	reg := b.Build()
+	if len(reg.DomainHashes) != 1 {
+		t.Fatalf("got %d domains, want 1", len(reg.DomainHashes))
+	}
+	if len(reg.URLHashes) != 1 || reg.IPs.Len() != 2 {
+		t.Fatalf("got %d urls, %d ips, want 1 and 2", len(reg.URLHashes), reg.IPs.Len())
+	}
+}
+
+func TestBuilder_Collisions(t *testing.T) {
+	b := newBuilder("test")
+	// Force every key into the same bucket.
+	b.hash = func(string) uint64 { return 42 }
+
+	for _, d := range []string{"a.com", "b.com", "a.com", "c.com"} {
+		b.AddDomain(d)
+	}
+
+	reg := b.Build()
+	if got := b.Stats().Collisions; got != 2 {
+		t.Fatalf("Collisions = %d, want 2", got)
+	}
+	if len(reg.DomainHashes) != 3 || reg.DomainKeys.Len() != 3 {
+		t.Fatalf("got %d hashes and %d keys, want 3 each", len(reg.DomainHashes), reg.DomainKeys.Len())
+	}
+	for i, want := range []string{"a.com", "b.com", "c.com"} {
+		if got := reg.DomainKeys.At(i); got != want {
+			t.Errorf("DomainKeys.At(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
@@ -4,23 +4,37 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
-	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"evil-rkn/internal/domain"
 )
 
 type Client struct {
+	// Timeout bounds a whole fetch of all lists, defaults to a minute.
+	Timeout time.Duration
+
 	baseURL string
 	http    *http.Client
+
+	mu         sync.Mutex
+	validators map[string]validators // by list path, from the last successful fetch
+}
+
+// validators are HTTP cache validators of a downloaded list.
+type validators struct {
+	etag         string
+	lastModified string
 }
 
 func NewClient(baseURL string) *Client {
 	return &Client{
+		Timeout: 60 * time.Second,
 		// Make sure we don’t end up with "//domains/" in the final URL.
 		baseURL: strings.TrimRight(baseURL, "/"),
 		http: &http.Client{
@@ -30,125 +44,154 @@ func NewClient(baseURL string) *Client {
 }
 
 // FetchRegistry implements the Fetcher interface.
-// It calls /api/v3/domains/ and builds a registry using only domain names.
+// It calls /api/v3/domains/, /api/v3/urls/ and /api/v3/ips/ and builds
+// a registry from them. The IP list may mix single addresses and subnets.
 func (c *Client) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
 	// Hard timeout for the whole operation, just to be safe.
-	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
 	defer cancel()
 
-	u, err := url.Parse(c.baseURL + "/domains/")
+	b := newBuilder(c.baseURL)
+	lists := []struct {
+		path string
+		add  func(string)
+	}{
+		{"/domains/", b.AddDomain},
+		{"/urls/", b.AddURL},
+		{"/ips/", b.AddIP},
+	}
+
+	c.mu.Lock()
+	prev := c.validators
+	c.mu.Unlock()
+
+	// First pass is conditional. If every list is unchanged there is
+	// nothing to rebuild; otherwise unchanged lists are downloaded again,
+	// because the registry is always built from all of them.
+	next := make(map[string]validators, len(lists))
+	var unchanged []int
+	for i, l := range lists {
+		v, notModified, err := c.fetchList(ctx, l.path, prev[l.path], &b.stats, l.add)
+		if err != nil {
+			return nil, attemptFailed(b.stats, err)
+		}
+		if notModified {
+			unchanged = append(unchanged, i)
+			continue
+		}
+		next[l.path] = v
+	}
+
+	if len(unchanged) == len(lists) {
+		log.Printf("rknapi: upstream lists not modified")
+		return nil, attemptFailed(b.stats, ErrNotModified)
+	}
+
+	for _, i := range unchanged {
+		l := lists[i]
+		v, _, err := c.fetchList(ctx, l.path, validators{}, &b.stats, l.add)
+		if err != nil {
+			return nil, attemptFailed(b.stats, err)
+		}
+		next[l.path] = v
+	}
+
+	reg := b.Build()
+	logBuild("rknapi", reg)
+
+	// Remember validators only once the whole registry is built, so a
+	// failed attempt is never mistaken for "not modified" later.
+	c.mu.Lock()
+	c.validators = next
+	c.mu.Unlock()
+
+	return reg, nil
+}
+
+// ForgetValidators implements the ValidatorForgetter interface: the next
+// fetch downloads every list unconditionally.
+func (c *Client) ForgetValidators() {
+	c.mu.Lock()
+	c.validators = nil
+	c.mu.Unlock()
+}
+
+// fetchList downloads a JSON array of strings from path and feeds every
+// element to add. Non-empty cond makes the request conditional; a 304
+// answer is reported as notModified and add is not called. The body is
+// decompressed on the fly and its sizes are added to st.
+func (c *Client) fetchList(ctx context.Context, path string, cond validators, st *domain.IngestStats, add func(string)) (v validators, notModified bool, err error) {
+	u, err := url.Parse(c.baseURL + path)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base url: %w", err)
+		return validators{}, false, fmt.Errorf("invalid base url: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return validators{}, false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	if cond.etag != "" {
+		req.Header.Set("If-None-Match", cond.etag)
+	}
+	if cond.lastModified != "" {
+		req.Header.Set("If-Modified-Since", cond.lastModified)
 	}
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
+		return validators{}, false, fmt.Errorf("do request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		return cond, true, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+		return validators{}, false, fmt.Errorf("%s: unexpected status: %s", path, resp.Status)
+	}
+
+	body, err := decodeStream(resp.Body, resp.Header.Get("Content-Encoding"), st)
+	if err != nil {
+		return validators{}, false, fmt.Errorf("%s: %w", path, err)
 	}
+	defer body.Close()
 
-	dec := json.NewDecoder(resp.Body)
+	if err := decodeJSONList(body, add); err != nil {
+		return validators{}, false, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return validators{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, false, nil
+}
+
+// decodeJSONList streams a JSON array of strings like
+// ["example.com", "foo.bar", ...] and feeds every element to add.
+func decodeJSONList(r io.Reader, add func(string)) error {
+	dec := json.NewDecoder(r)
 
-	// Expect a JSON array like: ["example.com", "foo.bar", ...].
 	t, err := dec.Token()
 	if err != nil {
-		return nil, fmt.Errorf("read opening token: %w", err)
+		return fmt.Errorf("read opening token: %w", err)
 	}
 	if d, ok := t.(json.Delim); !ok || d != '[' {
-		return nil, fmt.Errorf("expected JSON array from /domains/")
+		return fmt.Errorf("expected JSON array")
 	}
 
-	domainHashes := make([]uint64, 0, 1_000_000)
-
-	var (
-		skippedEmpty     int
-		skippedNormalize int
-		skippedBadDomain int
-		samples          []string
-	)
-
 	for dec.More() {
 		var raw string
 		if err := dec.Decode(&raw); err != nil {
-			return nil, fmt.Errorf("decode domain: %w", err)
-		}
-
-		raw = strings.TrimSpace(strings.ToLower(raw))
-		if raw == "" {
-			// Completely empty entry — just ignore it.
-			skippedEmpty++
-			continue
-		}
-		if strings.Contains(raw, "_") {
-			// RKN occasionally returns garbage like "bad_domain".
-			skippedBadDomain++
-			continue
-		}
-
-		// Run through our URL normalizer by faking a scheme.
-		host, err := domain.NormalizeHost(raw)
-		if err != nil {
-			skippedNormalize++
-			continue
+			return fmt.Errorf("decode entry: %w", err)
 		}
-
-		if len(samples) < 5 {
-			samples = append(samples, host)
-		}
-
-		domainHashes = append(domainHashes, domain.HashString64(host))
-
+		add(raw)
 	}
 
 	// Consume the closing ']' token.
 	if _, err := dec.Token(); err != nil {
-		return nil, fmt.Errorf("read closing token: %w", err)
-	}
-
-	// Sort and compact hashes to get rid of duplicates.
-	sort.Slice(domainHashes, func(i, j int) bool { return domainHashes[i] < domainHashes[j] })
-	domainHashes = compactUint64(domainHashes)
-
-	log.Printf("rknapi: skipped %d domains with '_' in name", skippedBadDomain)
-	log.Printf("rknapi: skipped %d domains due to normalize errors", skippedNormalize)
-	log.Printf("rknapi: skipped %d empty domains", skippedEmpty)
-	log.Printf("rknapi: registry built: %d domains, 0 urls, 0 ips", len(domainHashes))
-	for i, s := range samples {
-		log.Printf("rknapi: sample domain[%d]=%s", i, s)
-	}
-
-	reg := &domain.Registry{
-		DomainHashes: domainHashes,
-		URLHashes:    nil,
-		IPs:          make(map[string]struct{}),
+		return fmt.Errorf("read closing token: %w", err)
 	}
-	reg.LastUpdated = time.Now().UTC()
 
-	return reg, nil
-}
-
-// compactUint64 removes duplicates from a sorted slice.
-func compactUint64(src []uint64) []uint64 {
-	if len(src) == 0 {
-		return src
-	}
-
-	dst := src[:1]
-	last := src[0]
-	for _, v := range src[1:] {
-		if v != last {
-			dst = append(dst, v)
-			last = v
-		}
-	}
-	return dst
+	return nil
 }
@@ -0,0 +1,247 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"evil-rkn/internal/domain"
+)
+
+func newTestAPI(t *testing.T, routes map[string]string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_FetchRegistry_DomainsAndURLs(t *testing.T) {
+	srv := newTestAPI(t, map[string]string{
+		"/api/v3/domains/": `["Blocked.com", "", "bad_domain.com"]`,
+		"/api/v3/urls/":    `["http://example.com/page", "https://secure.example.org/path", "not a url"]`,
+		"/api/v3/ips/":     `["203.0.113.5", "198.51.100.0/24", "2001:db8::/32", "999.1.1.1", ""]`,
+	})
+
+	reg, err := NewClient(srv.URL + "/api/v3/").FetchRegistry(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+
+	if len(reg.DomainHashes) != 1 || len(reg.URLHashes) != 1 || len(reg.URLHostHashes) != 1 {
+		t.Fatalf("got %d domains, %d urls, %d url hosts, want 1 each",
+			len(reg.DomainHashes), len(reg.URLHashes), len(reg.URLHostHashes))
+	}
+	if reg.IPs.Len() != 3 {
+		t.Fatalf("got %d ips, want 3", reg.IPs.Len())
+	}
+
+	for raw, want := range map[string]bool{
+		"https://blocked.com/":             true,
+		"http://example.com/page":          true,
+		"http://example.com/other":         false,
+		"https://secure.example.org/other": true,
+		"http://203.0.113.5/":              true,
+		"http://198.51.100.42/":            true,
+		"http://[2001:db8::1]/":            true,
+		"http://192.0.2.1/":                false,
+	} {
+		n, err := domain.Normalize(raw)
+		if err != nil {
+			t.Fatalf("Normalize(%q) error: %v", raw, err)
+		}
+		if got := domain.IsBlocked(reg, n).Blocked; got != want {
+			t.Errorf("IsBlocked(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestClient_FetchRegistry_UpstreamError(t *testing.T) {
+	srv := newTestAPI(t, map[string]string{
+		"/api/v3/domains/": `["blocked.com"]`,
+	})
+
+	if _, err := NewClient(srv.URL + "/api/v3").FetchRegistry(context.Background()); err == nil {
+		t.Fatal("expected error when /urls/ and /ips/ are missing")
+	}
+}
+
+func TestClient_FetchRegistry_Conditional(t *testing.T) {
+	lists := map[string]string{
+		"/domains/": `["blocked.com"]`,
+		"/urls/":    `[]`,
+		"/ips/":     `[]`,
+	}
+	etags := map[string]string{"/domains/": `"d1"`, "/urls/": `"u1"`}
+	var full, conditional int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := lists[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		etag := etags[r.URL.Path]
+		lm := "Mon, 01 Jan 2024 00:00:00 GMT"
+
+		if inm := r.Header.Get("If-None-Match"); inm != "" {
+			conditional++
+			if inm == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		} else if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+			conditional++
+			if ims == lm {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		full++
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+		} else {
+			w.Header().Set("Last-Modified", lm)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	ctx := context.Background()
+
+	if _, err := c.FetchRegistry(ctx); err != nil {
+		t.Fatalf("first fetch error: %v", err)
+	}
+	if full != 3 || conditional != 0 {
+		t.Fatalf("first fetch: full=%d conditional=%d, want 3 and 0", full, conditional)
+	}
+
+	full, conditional = 0, 0
+	if _, err := c.FetchRegistry(ctx); !errors.Is(err, ErrNotModified) {
+		t.Fatalf("second fetch error = %v, want ErrNotModified", err)
+	}
+	if full != 0 || conditional != 3 {
+		t.Fatalf("second fetch: full=%d conditional=%d, want 0 and 3", full, conditional)
+	}
+
+	// One list changes: the unchanged ones are downloaded again.
+	lists["/domains/"] = `["blocked.com", "new.com"]`
+	etags["/domains/"] = `"d2"`
+	full, conditional = 0, 0
+	reg, err := c.FetchRegistry(ctx)
+	if err != nil {
+		t.Fatalf("third fetch error: %v", err)
+	}
+	if len(reg.DomainHashes) != 2 {
+		t.Fatalf("got %d domains, want 2", len(reg.DomainHashes))
+	}
+	if full != 3 {
+		t.Fatalf("third fetch: full=%d, want 3", full)
+	}
+}
+
+func TestUpdateOnce_RejectedClientRefetches(t *testing.T) {
+	domains := `["a.com", "b.com", "c.com", "d.com"]`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := `[]`
+		if r.URL.Path == "/domains/" {
+			body = domains
+		}
+		etag := fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(body)))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	holder := NewHolder()
+	guard, _ := NewGuard(GuardConfig{MaxDrop: 0.5})
+	ctx := context.Background()
+
+	if _, err := updateOnce(ctx, c, holder, guard); err != nil {
+		t.Fatalf("first update error: %v", err)
+	}
+	good := holder.Get()
+
+	domains = `["a.com"]`
+	if _, err := updateOnce(ctx, c, holder, guard); !errors.Is(err, ErrRejected) {
+		t.Fatalf("second update error = %v, want ErrRejected", err)
+	}
+
+	// Upstream still serves the truncated list. It must be judged again
+	// instead of passing as "not modified" and refreshing LastUpdated.
+	if _, err := updateOnce(ctx, c, holder, guard); !errors.Is(err, ErrRejected) {
+		t.Fatalf("third update error = %v, want ErrRejected", err)
+	}
+	if got := holder.Get(); got.LastUpdated != good.LastUpdated {
+		t.Fatalf("LastUpdated = %v, want %v from the last accepted registry", got.LastUpdated, good.LastUpdated)
+	}
+}
+
+func TestUpdateOnce_AttemptStats(t *testing.T) {
+	down := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`["blocked.com"]`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	holder := NewHolder()
+	ctx := context.Background()
+
+	res, err := updateOnce(ctx, c, holder, nil)
+	if err != nil {
+		t.Fatalf("first update error: %v", err)
+	}
+	if res.Stats.CompressedBytes == 0 {
+		t.Fatal("first update must report the bytes transferred")
+	}
+
+	// A 304 transfers nothing and must not repeat the first download.
+	res, err = updateOnce(ctx, c, holder, nil)
+	if err != nil || !res.NotModified {
+		t.Fatalf("second update = %v, %v, want not modified", res, err)
+	}
+	if res.Stats.Source != srv.URL || res.Stats.CompressedBytes != 0 || len(res.Stats.Samples) != 0 {
+		t.Fatalf("not modified Stats = %+v, want only the source", res.Stats)
+	}
+	if holder.Get().Stats.CompressedBytes == 0 {
+		t.Fatal("the served registry must keep the Stats of its download")
+	}
+
+	down = true
+	res, err = updateOnce(ctx, c, holder, nil)
+	if err == nil {
+		t.Fatal("expected error from a failing upstream")
+	}
+	if res.Stats.Source != srv.URL {
+		t.Fatalf("failed attempt source = %q, want %q", res.Stats.Source, srv.URL)
+	}
+}
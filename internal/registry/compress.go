@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"evil-rkn/internal/domain"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// acceptEncoding is sent with every upstream request. Setting it by hand
+// turns off the transparent gzip handling of net/http, so compressed
+// bytes can be counted before decoding.
+const acceptEncoding = "zstd, br, gzip"
+
+// encodingByExt maps pre-compressed file extensions to content codings.
+var encodingByExt = map[string]string{
+	".gz":  "gzip",
+	".zst": "zstd",
+	".br":  "br",
+}
+
+// splitCompressedExt strips a compression extension from a file name:
+// "domains.json.gz" gives "domains.json" and "gzip".
+func splitCompressedExt(name string) (base, encoding string) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if enc, ok := encodingByExt[ext]; ok {
+		return strings.TrimSuffix(name, filepath.Ext(name)), enc
+	}
+	return name, ""
+}
+
+// decodeStream returns a reader yielding r decoded according to a
+// Content-Encoding value. Data is decompressed on the fly, never buffered
+// as a whole. Bytes read before and after decoding are added to st.
+// Closing the result releases the decoder, not r.
+func decodeStream(r io.Reader, encoding string, st *domain.IngestStats) (io.ReadCloser, error) {
+	raw := &byteCounter{r: r, n: &st.CompressedBytes}
+
+	var dec io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		dec = io.NopCloser(raw)
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(raw)
+		if err != nil {
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+		dec = zr
+	case "zstd":
+		zr, err := zstd.NewReader(raw)
+		if err != nil {
+			return nil, fmt.Errorf("zstd: %w", err)
+		}
+		dec = zr.IOReadCloser()
+	case "br":
+		dec = io.NopCloser(brotli.NewReader(raw))
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+
+	return &decodedReader{
+		Reader: &byteCounter{r: dec, n: &st.DecompressedBytes},
+		dec:    dec,
+	}, nil
+}
+
+// byteCounter adds the number of bytes read through it to *n.
+type byteCounter struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *byteCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+type decodedReader struct {
+	io.Reader
+	dec io.Closer
+}
+
+func (d *decodedReader) Close() error {
+	return d.dec.Close()
+}
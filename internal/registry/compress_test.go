@@ -0,0 +1,133 @@
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	switch encoding {
+	case "gzip":
+		w := gzip.NewWriter(&buf)
+		_, _ = w.Write(data)
+		_ = w.Close()
+	case "zstd":
+		w, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write(data)
+		_ = w.Close()
+	case "br":
+		w := brotli.NewWriter(&buf)
+		_, _ = w.Write(data)
+		_ = w.Close()
+	default:
+		t.Fatalf("unknown encoding %q", encoding)
+	}
+	return buf.Bytes()
+}
+
+func TestClient_FetchRegistry_ContentEncoding(t *testing.T) {
+	domains := []byte(`["blocked.com", "` + strings.Repeat("a", 1000) + `.com"]`)
+
+	for _, enc := range []string{"gzip", "zstd", "br"} {
+		t.Run(enc, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.Contains(r.Header.Get("Accept-Encoding"), enc) {
+					t.Errorf("Accept-Encoding = %q, want it to contain %s", r.Header.Get("Accept-Encoding"), enc)
+				}
+				body := []byte(`[]`)
+				if r.URL.Path == "/domains/" {
+					body = domains
+				}
+				w.Header().Set("Content-Encoding", enc)
+				_, _ = w.Write(compress(t, enc, body))
+			}))
+			defer srv.Close()
+
+			reg, err := NewClient(srv.URL).FetchRegistry(context.Background())
+			if err != nil {
+				t.Fatalf("FetchRegistry error: %v", err)
+			}
+			if len(reg.DomainHashes) != 2 {
+				t.Fatalf("got %d domains, want 2", len(reg.DomainHashes))
+			}
+
+			st := reg.Stats
+			if want := int64(len(domains) + 2*len(`[]`)); st.DecompressedBytes != want {
+				t.Errorf("DecompressedBytes = %d, want %d", st.DecompressedBytes, want)
+			}
+			if st.CompressedBytes == 0 || st.CompressedBytes >= st.DecompressedBytes {
+				t.Errorf("CompressedBytes = %d, want 0 < n < %d", st.CompressedBytes, st.DecompressedBytes)
+			}
+		})
+	}
+}
+
+func TestClient_FetchRegistry_UnknownEncoding(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "compress")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL).FetchRegistry(context.Background()); err == nil {
+		t.Fatal("expected error for unsupported content encoding")
+	}
+}
+
+func TestFileSource_Compressed(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"domains.json.gz": compress(t, "gzip", []byte(`["blocked.com"]`)),
+		"urls.json.zst":   compress(t, "zstd", []byte(`["http://example.com/page"]`)),
+		"ips.json.br":     compress(t, "br", []byte(`["203.0.113.5"]`)),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reg, err := NewFileSource(dir).FetchRegistry(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+	checkBlocked(t, reg, map[string]bool{
+		"https://blocked.com/":    true,
+		"http://example.com/page": true,
+		"http://203.0.113.5/":     true,
+	})
+	if reg.Stats.CompressedBytes == reg.Stats.DecompressedBytes {
+		t.Errorf("expected different compressed and decompressed sizes, got %d", reg.Stats.CompressedBytes)
+	}
+}
+
+func TestUpdateOnce_ReportsBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "domains.json.gz")
+	if err := os.WriteFile(path, compress(t, "gzip", []byte(`["blocked.com"]`)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := updateOnce(context.Background(), NewFileSource(path), NewHolder(), nil)
+	if err != nil {
+		t.Fatalf("updateOnce error: %v", err)
+	}
+	if res.Domains != 1 || res.Stats.DecompressedBytes != int64(len(`["blocked.com"]`)) || res.Stats.CompressedBytes == 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
@@ -0,0 +1,181 @@
+package registry
+
+import (
+	"iter"
+	"net/netip"
+	"time"
+
+	"evil-rkn/internal/domain"
+)
+
+// diffEntryLimit caps the entries listed per kind and direction, so the
+// first load of a million-entry registry does not pin a million strings
+// in the history. The counts are always exact.
+const diffEntryLimit = 1000
+
+// Diff describes what changed between two registry versions.
+type Diff struct {
+	FromVersion uint64
+	ToVersion   uint64
+	At          time.Time // when ToVersion was swapped in
+
+	Domains    KindDiff
+	URLs       KindDiff // http URL entries, see domain.URLKey
+	HTTPSHosts KindDiff // hosts of https URL entries
+	IPs        KindDiff // single addresses
+	Subnets    KindDiff
+}
+
+// KindDiff lists the entries of one kind added and removed. Entries are
+// only known when both registries keep keys (see domain.KeyTable),
+// otherwise only the counts are filled.
+type KindDiff struct {
+	Added   []string
+	Removed []string
+
+	AddedCount   int
+	RemovedCount int
+}
+
+// Truncated reports whether some changed entries are not listed.
+func (d KindDiff) Truncated() bool {
+	return len(d.Added) < d.AddedCount || len(d.Removed) < d.RemovedCount
+}
+
+// Empty reports whether nothing changed.
+func (d KindDiff) Empty() bool {
+	return d.AddedCount == 0 && d.RemovedCount == 0
+}
+
+// Empty reports whether the two versions hold the same entries.
+func (d Diff) Empty() bool {
+	return d.Domains.Empty() && d.URLs.Empty() && d.HTTPSHosts.Empty() && d.IPs.Empty() && d.Subnets.Empty()
+}
+
+func (d *KindDiff) add(s string) {
+	if len(d.Added) < diffEntryLimit {
+		d.Added = append(d.Added, s)
+	}
+	d.AddedCount++
+}
+
+func (d *KindDiff) remove(s string) {
+	if len(d.Removed) < diffEntryLimit {
+		d.Removed = append(d.Removed, s)
+	}
+	d.RemovedCount++
+}
+
+// computeDiff compares two registries entry by entry.
+func computeDiff(prev, next *domain.Registry) Diff {
+	d := Diff{FromVersion: prev.Version, ToVersion: next.Version}
+	d.Domains = diffKeys(prev.DomainHashes, prev.DomainKeys, next.DomainHashes, next.DomainKeys)
+	d.URLs = diffKeys(prev.URLHashes, prev.URLKeys, next.URLHashes, next.URLKeys)
+	d.HTTPSHosts = diffKeys(prev.URLHostHashes, prev.URLHostKeys, next.URLHostHashes, next.URLHostKeys)
+	d.IPs, d.Subnets = diffIPs(prev.IPs, next.IPs)
+	return d
+}
+
+// diffKeys merges two slices sorted by (hash, key), as built by the
+// builder. Without keys on both sides entries are told apart by hash only.
+func diffKeys(ah []uint64, ak *domain.KeyTable, bh []uint64, bk *domain.KeyTable) KindDiff {
+	var d KindDiff
+	keyed := ak.Len() == len(ah) && bk.Len() == len(bh)
+
+	key := func(t *domain.KeyTable, i int) string {
+		if !keyed {
+			return ""
+		}
+		return t.At(i)
+	}
+
+	i, j := 0, 0
+	for i < len(ah) || j < len(bh) {
+		switch {
+		case j == len(bh) || (i < len(ah) && ah[i] < bh[j]):
+			d.remove(key(ak, i))
+			i++
+		case i == len(ah) || bh[j] < ah[i]:
+			d.add(key(bk, j))
+			j++
+		default: // same hash
+			if !keyed {
+				i++
+				j++
+				continue
+			}
+			switch a, b := ak.At(i), bk.At(j); {
+			case a < b:
+				d.remove(a)
+				i++
+			case b < a:
+				d.add(b)
+				j++
+			default:
+				i++
+				j++
+			}
+		}
+	}
+
+	if !keyed {
+		d.Added, d.Removed = nil, nil
+	}
+	return d
+}
+
+// diffIPs merges two sets walked in address order and splits the result
+// into single addresses and subnets.
+func diffIPs(a, b *domain.IPSet) (ips, subnets KindDiff) {
+	pick := func(p netip.Prefix) (*KindDiff, string) {
+		if p.IsSingleIP() {
+			return &ips, p.Addr().String()
+		}
+		return &subnets, p.String()
+	}
+
+	nextA, stopA := iter.Pull(a.All())
+	defer stopA()
+	nextB, stopB := iter.Pull(b.All())
+	defer stopB()
+
+	pa, okA := nextA()
+	pb, okB := nextB()
+	for okA || okB {
+		switch c := comparePrefix(pa, okA, pb, okB); {
+		case c < 0:
+			d, s := pick(pa)
+			d.remove(s)
+			pa, okA = nextA()
+		case c > 0:
+			d, s := pick(pb)
+			d.add(s)
+			pb, okB = nextB()
+		default:
+			pa, okA = nextA()
+			pb, okB = nextB()
+		}
+	}
+	return ips, subnets
+}
+
+// comparePrefix orders prefixes the way IPSet.All yields them; an
+// exhausted side sorts last.
+func comparePrefix(a netip.Prefix, okA bool, b netip.Prefix, okB bool) int {
+	switch {
+	case !okA:
+		return 1
+	case !okB:
+		return -1
+	}
+	if a.Addr().Is4() != b.Addr().Is4() {
+		if a.Addr().Is4() {
+			return -1
+		}
+		return 1
+	}
+	if c := a.Addr().Compare(b.Addr()); c != 0 {
+		return c
+	}
+	return a.Bits() - b.Bits()
+}
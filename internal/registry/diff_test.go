@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"evil-rkn/internal/domain"
+)
+
+func buildRegistry(domains, urls, ips []string) *domain.Registry {
+	b := newBuilder("test")
+	addAll(b.AddDomain, domains)
+	addAll(b.AddURL, urls)
+	addAll(b.AddIP, ips)
+	return b.Build()
+}
+
+func TestComputeDiff(t *testing.T) {
+	prev := buildRegistry(
+		[]string{"kept.com", "removed.com"},
+		[]string{"http://kept.com/a", "http://gone.com/b", "https://secure-gone.com/"},
+		[]string{"192.0.2.1", "198.51.100.0/24", "2001:db8::/32"},
+	)
+	next := buildRegistry(
+		[]string{"kept.com", "added.com", "another.com"},
+		[]string{"http://kept.com/a", "https://secure-new.com/x"},
+		[]string{"192.0.2.1", "192.0.2.2", "2001:db8::/48"},
+	)
+
+	d := computeDiff(prev, next)
+
+	check := func(name string, got KindDiff, added, removed []string) {
+		t.Helper()
+		if !reflect.DeepEqual(sorted(got.Added), sorted(added)) || !reflect.DeepEqual(sorted(got.Removed), sorted(removed)) {
+			t.Errorf("%s: got +%v -%v, want +%v -%v", name, got.Added, got.Removed, added, removed)
+		}
+		if got.AddedCount != len(added) || got.RemovedCount != len(removed) {
+			t.Errorf("%s: counts +%d -%d, want +%d -%d", name, got.AddedCount, got.RemovedCount, len(added), len(removed))
+		}
+	}
+	check("domains", d.Domains, []string{"added.com", "another.com"}, []string{"removed.com"})
+	check("urls", d.URLs, nil, []string{"http://gone.com/b"})
+	check("https hosts", d.HTTPSHosts, []string{"secure-new.com"}, []string{"secure-gone.com"})
+	check("ips", d.IPs, []string{"192.0.2.2"}, nil)
+	check("subnets", d.Subnets, []string{"2001:db8::/48"}, []string{"198.51.100.0/24", "2001:db8::/32"})
+
+	if d.Empty() || !computeDiff(next, next).Empty() {
+		t.Fatal("Empty() is wrong")
+	}
+}
+
+func TestComputeDiff_WithoutKeys(t *testing.T) {
+	prev := &domain.Registry{DomainHashes: []uint64{1, 2, 3}}
+	next := &domain.Registry{DomainHashes: []uint64{2, 3, 4, 5}}
+
+	d := computeDiff(prev, next).Domains
+	if d.AddedCount != 2 || d.RemovedCount != 1 || d.Added != nil || d.Removed != nil {
+		t.Fatalf("got %+v, want counts only: +2 -1", d)
+	}
+}
+
+func TestComputeDiff_Truncated(t *testing.T) {
+	var domains []string
+	for i := 0; i < diffEntryLimit+10; i++ {
+		domains = append(domains, fmt.Sprintf("d%d.example.com", i))
+	}
+
+	d := computeDiff(NewHolder().Get(), buildRegistry(domains, nil, nil)).Domains
+	if d.AddedCount != len(domains) || len(d.Added) != diffEntryLimit || !d.Truncated() {
+		t.Fatalf("AddedCount = %d, listed %d, want %d and %d", d.AddedCount, len(d.Added), len(domains), diffEntryLimit)
+	}
+}
+
+func sorted(s []string) []string {
+	out := append([]string(nil), s...)
+	slices.Sort(out)
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package registry
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"evil-rkn/internal/domain"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// DumpCSVSource reads the registry from the zapret-info dump.csv mirror.
+// Location is an http(s) URL, a file:// URL or a filesystem path.
+type DumpCSVSource struct {
+	// Timeout bounds a whole fetch: download, decompression and
+	// parsing. Dumps run to hundreds of megabytes, so it defaults to
+	// DefaultDumpTimeout.
+	Timeout time.Duration
+
+	location string
+}
+
+func NewDumpCSVSource(location string) *DumpCSVSource {
+	return &DumpCSVSource{Timeout: DefaultDumpTimeout, location: location}
+}
+
+// FetchRegistry implements the Fetcher interface.
+func (s *DumpCSVSource) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	b := newBuilder(s.location)
+
+	rc, err := openLocation(ctx, s.location, &b.stats)
+	if err != nil {
+		return nil, attemptFailed(b.stats, fmt.Errorf("dump.csv: %w", err))
+	}
+	defer rc.Close()
+
+	if err := parseDumpCSV(rc, b); err != nil {
+		return nil, attemptFailed(b.stats, fmt.Errorf("dump.csv: %w", err))
+	}
+
+	reg := b.Build()
+	logBuild("dump.csv", reg)
+	return reg, nil
+}
+
+// parseDumpCSV reads a windows-1251 encoded dump.csv:
+//
+//	Updated: 2024-05-01 10:00:00 +0000
+//	ip1 | subnet2;domain1 | domain2;url1 | url2;org;decision;date
+//
+// Like dump.xml "default" entries, a record is enforced by its URLs,
+// then by its domains if it has no URLs, then by its IPs if it has neither.
+func parseDumpCSV(r io.Reader, b *builder) error {
+	sc := bufio.NewScanner(charmap.Windows1251.NewDecoder().Reader(r))
+	// Some records list thousands of IPs on one line.
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var records int
+	first := true
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if first {
+			first = false
+			if strings.HasPrefix(line, "Updated:") {
+				log.Printf("dump.csv: %s", line)
+				continue
+			}
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, ";")
+		if len(fields) < 3 {
+			b.stats.Malformed++
+			continue
+		}
+		records++
+
+		ips, domains := splitMulti(fields[0]), splitMulti(fields[1])
+		// URLs may contain ';' themselves, the last three columns never do.
+		urlField := fields[2]
+		if len(fields) > 6 {
+			urlField = strings.Join(fields[2:len(fields)-3], ";")
+		}
+		urls := splitMulti(urlField)
+
+		switch {
+		case len(urls) > 0:
+			addAll(b.AddURL, urls)
+		case len(domains) > 0:
+			for _, d := range domains {
+				b.AddDomain(strings.TrimPrefix(d, "*."))
+			}
+		default:
+			addAll(b.AddIP, ips)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+
+	if records == 0 {
+		return fmt.Errorf("no records found")
+	}
+	return nil
+}
+
+// splitMulti splits a " | " separated column, dropping blank values.
+func splitMulti(field string) []string {
+	var out []string
+	for _, v := range strings.Split(field, "|") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testDumpCSV = strings.Join([]string{
+	"Updated: 2024-05-01 10:00:00 +0000",
+	"192.0.2.10 | 192.0.2.11;url.example;http://url.example/page | https://secure.example/path;Роскомнадзор;27-31-2018/Ид2971-18;2018-04-16",
+	"192.0.2.20;пример.рф | *.mask.example;;Генпрокуратура;1;2019-01-01",
+	"203.0.113.5 | 198.51.100.0/24 | 2001:db8::/32;;;суд;2-1/2020;2020-02-02",
+	"not-an-ip;;;суд;3;2020-02-02",
+	"http://a.example/x;y",
+	"192.0.2.30;semi.example;http://semi.example/a;b=1;суд;4;2021-03-03",
+	"",
+}, "\r\n")
+
+func TestDumpCSVSource_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.csv")
+	writeCP1251(t, path, testDumpCSV)
+
+	src := NewDumpCSVSource("file://" + path)
+	reg, err := src.FetchRegistry(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+
+	checkBlocked(t, reg, map[string]bool{
+		"http://url.example/page":       true,
+		"http://url.example/other":      false,
+		"https://secure.example/":       true,
+		"http://192.0.2.10/":            false,
+		"https://пример.рф/":            true,
+		"https://www.mask.example/":     true,
+		"http://192.0.2.20/":            false,
+		"http://203.0.113.5/":           true,
+		"http://198.51.100.200/":        true,
+		"http://[2001:db8::1]/":         true,
+		"http://semi.example/a;b=1":     true,
+		"http://semi.example/elsewhere": false,
+	})
+}
+
+func TestParseDumpCSV_Stats(t *testing.T) {
+	b := newBuilder("test")
+	if err := parseDumpCSV(strings.NewReader(testDumpCSV), b); err != nil {
+		t.Fatalf("parseDumpCSV error: %v", err)
+	}
+
+	st := b.Stats()
+	if st.Malformed != 1 {
+		t.Errorf("Malformed = %d, want 1", st.Malformed)
+	}
+	if st.IPs.Invalid != 1 {
+		t.Errorf("IPs.Invalid = %d, want 1", st.IPs.Invalid)
+	}
+}
+
+func TestParseDumpCSV_NoRecords(t *testing.T) {
+	if err := parseDumpCSV(strings.NewReader("Updated: 2024-05-01 10:00:00 +0000\n"), newBuilder("test")); err == nil {
+		t.Fatal("expected error for a dump without records")
+	}
+}
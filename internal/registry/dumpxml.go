@@ -0,0 +1,168 @@
+package registry
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"evil-rkn/internal/domain"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// DumpXMLSource reads the registry from the official RKN dump.xml.
+// Location is an http(s) URL, a file:// URL or a filesystem path.
+type DumpXMLSource struct {
+	// Timeout bounds a whole fetch: download, decompression and
+	// parsing. Dumps run to hundreds of megabytes, so it defaults to
+	// DefaultDumpTimeout.
+	Timeout time.Duration
+
+	location string
+}
+
+func NewDumpXMLSource(location string) *DumpXMLSource {
+	return &DumpXMLSource{Timeout: DefaultDumpTimeout, location: location}
+}
+
+// FetchRegistry implements the Fetcher interface.
+func (s *DumpXMLSource) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	b := newBuilder(s.location)
+
+	rc, err := openLocation(ctx, s.location, &b.stats)
+	if err != nil {
+		return nil, attemptFailed(b.stats, fmt.Errorf("dump.xml: %w", err))
+	}
+	defer rc.Close()
+
+	if err := parseDumpXML(rc, b); err != nil {
+		return nil, attemptFailed(b.stats, fmt.Errorf("dump.xml: %w", err))
+	}
+
+	reg := b.Build()
+	logBuild("dump.xml", reg)
+	return reg, nil
+}
+
+// dumpContent is a single <content> entry of dump.xml.
+type dumpContent struct {
+	BlockType   string   `xml:"blockType,attr"`
+	URLs        []string `xml:"url"`
+	Domains     []string `xml:"domain"`
+	DomainMasks []string `xml:"domain-mask"` // legacy element, <domain> is used nowadays
+	IPs         []string `xml:"ip"`
+	IPSubnets   []string `xml:"ipSubnet"`
+	IPv6s       []string `xml:"ipv6"`
+	IPv6Subnets []string `xml:"ipv6Subnet"`
+}
+
+// parseDumpXML streams <content> entries into b one by one, so the
+// multi-hundred-megabyte document is never held in memory.
+//
+// blockType decides which parts of an entry are enforced:
+//   - "default" (or absent): the listed URLs; the domain if no URL is given.
+//     IPs of such entries are only resolver hints and are ignored.
+//   - "domain": the listed domains.
+//   - "domain-mask": the masks given in <domain> (or the older <domain-mask>),
+//     "*.example.com" blocks example.com and below.
+//   - "ip": the listed addresses and subnets.
+func parseDumpXML(r io.Reader, b *builder) error {
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charsetReader
+
+	var entries, unknown int
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read token: %w", err)
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch se.Name.Local {
+		case "register":
+			for _, a := range se.Attr {
+				if a.Name.Local == "updateTime" {
+					log.Printf("dump.xml: updateTime=%s", a.Value)
+				}
+			}
+		case "content":
+			var c dumpContent
+			if err := dec.DecodeElement(&c, &se); err != nil {
+				return fmt.Errorf("decode content: %w", err)
+			}
+			entries++
+			if !addDumpContent(b, &c) {
+				unknown++
+			}
+		}
+	}
+
+	if entries == 0 {
+		return fmt.Errorf("no <content> entries found")
+	}
+	if unknown > 0 {
+		log.Printf("dump.xml: skipped %d entries with unknown blockType", unknown)
+	}
+	return nil
+}
+
+// addDumpContent feeds one entry into b according to its blockType.
+// It reports false for an unknown blockType.
+func addDumpContent(b *builder, c *dumpContent) bool {
+	switch strings.TrimSpace(c.BlockType) {
+	case "", "default":
+		if len(c.URLs) == 0 {
+			addAll(b.AddDomain, c.Domains)
+			return true
+		}
+		addAll(b.AddURL, c.URLs)
+	case "domain":
+		addAll(b.AddDomain, c.Domains)
+	case "domain-mask":
+		masks := c.Domains
+		if len(masks) == 0 {
+			masks = c.DomainMasks
+		}
+		for _, m := range masks {
+			b.AddDomain(strings.TrimPrefix(strings.TrimSpace(m), "*."))
+		}
+	case "ip":
+		addAll(b.AddIP, c.IPs)
+		addAll(b.AddIP, c.IPSubnets)
+		addAll(b.AddIP, c.IPv6s)
+		addAll(b.AddIP, c.IPv6Subnets)
+	default:
+		return false
+	}
+	return true
+}
+
+func addAll(add func(string), values []string) {
+	for _, v := range values {
+		add(v)
+	}
+}
+
+// charsetReader lets encoding/xml read windows-1251 dumps.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(label) {
+	case "windows-1251", "cp1251":
+		return charmap.Windows1251.NewDecoder().Reader(input), nil
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", label)
+	}
+}
@@ -0,0 +1,125 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"evil-rkn/internal/domain"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const testDumpXML = `<?xml version="1.0" encoding="windows-1251"?>
+<reg:register updateTime="2024-05-01T10:00:00+03:00" formatVersion="2.4" xmlns:reg="http://rsoc.ru" xmlns:tns="http://rsoc.ru">
+<content id="1" includeTime="2013-01-01T00:00:00" entryType="1" hash="a">
+  <decision date="2013-01-01" number="1" org="ФСКН"/>
+  <url><![CDATA[http://url-only.example/page]]></url>
+  <domain><![CDATA[url-only.example]]></domain>
+  <ip>192.0.2.10</ip>
+</content>
+<content id="2" includeTime="2013-01-01T00:00:00" entryType="1" blockType="default" hash="b">
+  <decision date="2013-01-01" number="2" org="ФСКН"/>
+  <domain><![CDATA[пример.рф]]></domain>
+</content>
+<content id="3" includeTime="2013-01-01T00:00:00" entryType="1" blockType="domain" hash="c">
+  <decision date="2013-01-01" number="3" org="Генпрокуратура"/>
+  <url><![CDATA[http://domain.example/ignored]]></url>
+  <domain><![CDATA[domain.example]]></domain>
+</content>
+<content id="4" includeTime="2013-01-01T00:00:00" entryType="1" blockType="domain-mask" hash="d">
+  <decision date="2013-01-01" number="4" org="суд"/>
+  <domain><![CDATA[*.mask.example]]></domain>
+</content>
+<content id="5" includeTime="2013-01-01T00:00:00" entryType="1" blockType="ip" hash="e">
+  <decision date="2013-01-01" number="5" org="суд"/>
+  <ip>203.0.113.5</ip>
+  <ipSubnet>198.51.100.0/24</ipSubnet>
+  <ipv6>2001:db8::1</ipv6>
+  <ipv6Subnet>2001:db8:1::/48</ipv6Subnet>
+</content>
+<content id="6" blockType="something-new">
+  <domain>future.example</domain>
+</content>
+</reg:register>
+`
+
+func writeCP1251(t *testing.T, path, s string) {
+	t.Helper()
+
+	enc, err := charmap.Windows1251.NewEncoder().String(s)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(enc), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func checkBlocked(t *testing.T, reg *domain.Registry, cases map[string]bool) {
+	t.Helper()
+
+	for raw, want := range cases {
+		n, err := domain.Normalize(raw)
+		if err != nil {
+			t.Fatalf("Normalize(%q) error: %v", raw, err)
+		}
+		if got := domain.IsBlocked(reg, n).Blocked; got != want {
+			t.Errorf("IsBlocked(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestDumpXMLSource_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.xml")
+	writeCP1251(t, path, testDumpXML)
+
+	reg, err := NewDumpXMLSource(path).FetchRegistry(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+
+	checkBlocked(t, reg, map[string]bool{
+		"http://url-only.example/page":    true,
+		"http://url-only.example/other":   false,
+		"http://192.0.2.10/":              false,
+		"https://пример.рф/":              true,
+		"https://domain.example/anything": true,
+		"https://a.mask.example/":         true,
+		"http://203.0.113.5/":             true,
+		"http://198.51.100.7/":            true,
+		"http://[2001:db8::1]/":           true,
+		"http://[2001:db8:1::9]/":         true,
+		"https://future.example/":         false,
+	})
+}
+
+func TestDumpXMLSource_HTTP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.xml")
+	writeCP1251(t, path, testDumpXML)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, path)
+	}))
+	defer srv.Close()
+
+	reg, err := NewDumpXMLSource(srv.URL + "/dump.xml").FetchRegistry(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+	checkBlocked(t, reg, map[string]bool{"https://domain.example/": true})
+}
+
+func TestDumpXMLSource_Empty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.xml")
+	if err := os.WriteFile(path, []byte(`<?xml version="1.0"?><register></register>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewDumpXMLSource(path).FetchRegistry(context.Background()); err == nil {
+		t.Fatal("expected error for a dump without entries")
+	}
+}
@@ -0,0 +1,154 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"evil-rkn/internal/domain"
+)
+
+// FailoverSource tries an ordered list of sources (mirrors, possibly of
+// different formats) and returns the registry of the first one that
+// succeeds. The registry's Stats.Source tells which one it was.
+//
+// With a non-zero CrossCheck the next healthy source is fetched as well
+// and the entry counts are compared: if the preferred registry is smaller
+// than the other by more than CrossCheck (a fraction, 0.2 = 20%), it is
+// considered truncated and the bigger one is used instead.
+//
+// Every source bounds its own fetch, so a mirror that hangs costs its
+// own timeout and the next one is still tried.
+//
+// FailoverSource is driven by the single updater goroutine and is not
+// safe for concurrent FetchRegistry calls.
+type FailoverSource struct {
+	sources    []Fetcher
+	CrossCheck float64
+
+	active int // index of the source that produced the current registry, -1 if none
+}
+
+func NewFailoverSource(sources ...Fetcher) *FailoverSource {
+	return &FailoverSource{sources: sources, active: -1}
+}
+
+// FetchRegistry implements the Fetcher interface.
+func (f *FailoverSource) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+	var (
+		errs       []error
+		best       *domain.Registry
+		bestIdx    = -1
+		candidates int
+	)
+
+	for i, src := range f.sources {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		reg, err := src.FetchRegistry(ctx)
+		if errors.Is(err, ErrNotModified) {
+			if i == f.active && best == nil {
+				return nil, err
+			}
+			// Unchanged since its own last fetch, but its data is not
+			// what we serve now: a recovered primary or the cross-check
+			// partner still has to be looked at, so fetch it in full.
+			vf, ok := src.(ValidatorForgetter)
+			if !ok {
+				continue
+			}
+			vf.ForgetValidators()
+			reg, err = src.FetchRegistry(ctx)
+		}
+		if err != nil {
+			log.Printf("registry: source #%d failed: %v", i, err)
+			errs = append(errs, fmt.Errorf("source #%d: %w", i, err))
+			continue
+		}
+
+		candidates++
+		if best == nil {
+			best, bestIdx = reg, i
+		} else if truncated(best, reg, f.CrossCheck) {
+			log.Printf("registry: source #%d (%s) looks truncated: %d entries vs %d from source #%d (%s)",
+				bestIdx, best.Stats.Source, entryCount(best), entryCount(reg), i, reg.Stats.Source)
+			best, bestIdx = reg, i
+		}
+
+		if f.CrossCheck <= 0 || candidates == 2 {
+			break
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("all %d registry sources failed: %w", len(f.sources), errors.Join(errs...))
+	}
+
+	if bestIdx != f.active {
+		log.Printf("registry: serving data from source #%d (%s)", bestIdx, best.Stats.Source)
+	}
+	f.active = bestIdx
+	return best, nil
+}
+
+// ForgetValidators implements the ValidatorForgetter interface for every
+// source that supports it.
+func (f *FailoverSource) ForgetValidators() {
+	for _, src := range f.sources {
+		if vf, ok := src.(ValidatorForgetter); ok {
+			vf.ForgetValidators()
+		}
+	}
+}
+
+// Watch implements the Watcher interface by merging the change signals
+// of all sources that support watching.
+func (f *FailoverSource) Watch(ctx context.Context) <-chan struct{} {
+	var chans []<-chan struct{}
+	for _, src := range f.sources {
+		if w, ok := src.(Watcher); ok {
+			if ch := w.Watch(ctx); ch != nil {
+				chans = append(chans, ch)
+			}
+		}
+	}
+	if len(chans) == 0 {
+		return nil
+	}
+
+	out := make(chan struct{}, 1)
+	for _, ch := range chans {
+		go func(ch <-chan struct{}) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case _, ok := <-ch:
+					if !ok {
+						return
+					}
+					select {
+					case out <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}(ch)
+	}
+	return out
+}
+
+// truncated reports whether a has more than tolerance fewer entries than b.
+func truncated(a, b *domain.Registry, tolerance float64) bool {
+	na, nb := float64(entryCount(a)), float64(entryCount(b))
+	return nb > 0 && na < nb*(1-tolerance)
+}
+
+// entryCount returns the total number of entries of all kinds.
+func entryCount(reg *domain.Registry) int {
+	return len(reg.DomainHashes) + len(reg.URLHashes) + len(reg.URLHostHashes) + reg.IPs.Len()
+}
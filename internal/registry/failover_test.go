@@ -0,0 +1,200 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"evil-rkn/internal/domain"
+)
+
+// countingSource returns a registry with n domains, or err.
+type countingSource struct {
+	name  string
+	n     int
+	err   error
+	calls int
+}
+
+func (s *countingSource) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	hs := make([]uint64, s.n)
+	for i := range hs {
+		hs[i] = uint64(i)
+	}
+	return &domain.Registry{DomainHashes: hs, Stats: domain.IngestStats{Source: s.name}}, nil
+}
+
+// conditionalSource answers ErrNotModified after a successful fetch, like
+// a Client whose lists did not change, until its validators are forgotten.
+type conditionalSource struct {
+	countingSource
+	valid bool
+}
+
+func (s *conditionalSource) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+	if s.valid && s.err == nil {
+		s.calls++
+		return nil, ErrNotModified
+	}
+	reg, err := s.countingSource.FetchRegistry(ctx)
+	if err == nil {
+		s.valid = true
+	}
+	return reg, err
+}
+
+func (s *conditionalSource) ForgetValidators() { s.valid = false }
+
+func TestFailoverSource_PriorityOrder(t *testing.T) {
+	primary := &countingSource{name: "primary", n: 10}
+	mirror := &countingSource{name: "mirror", n: 10}
+	f := NewFailoverSource(primary, mirror)
+
+	reg, err := f.FetchRegistry(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+	if reg.Stats.Source != "primary" || mirror.calls != 0 {
+		t.Fatalf("source = %s, mirror calls = %d, want primary and 0", reg.Stats.Source, mirror.calls)
+	}
+}
+
+func TestFailoverSource_FallsBack(t *testing.T) {
+	primary := &countingSource{name: "primary", err: errors.New("down")}
+	mirror := &countingSource{name: "mirror", n: 5}
+	f := NewFailoverSource(primary, mirror)
+
+	reg, err := f.FetchRegistry(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+	if reg.Stats.Source != "mirror" {
+		t.Fatalf("source = %s, want mirror", reg.Stats.Source)
+	}
+
+	mirror.err = errors.New("down too")
+	if _, err := f.FetchRegistry(context.Background()); err == nil {
+		t.Fatal("expected error when all sources fail")
+	}
+}
+
+func TestFailoverSource_NotModified(t *testing.T) {
+	primary := &countingSource{name: "primary", n: 5}
+	mirror := &countingSource{name: "mirror", n: 5}
+	f := NewFailoverSource(primary, mirror)
+
+	if _, err := f.FetchRegistry(context.Background()); err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+
+	// The active source is unchanged: nothing to do.
+	primary.err = ErrNotModified
+	if _, err := f.FetchRegistry(context.Background()); !errors.Is(err, ErrNotModified) {
+		t.Fatalf("err = %v, want ErrNotModified", err)
+	}
+
+	// A non-active source being unchanged does not count.
+	primary.err = errors.New("down")
+	if reg, err := f.FetchRegistry(context.Background()); err != nil || reg.Stats.Source != "mirror" {
+		t.Fatalf("got %v, %v, want registry from mirror", reg, err)
+	}
+	primary.err = ErrNotModified
+	if reg, err := f.FetchRegistry(context.Background()); err != nil || reg.Stats.Source != "mirror" {
+		t.Fatalf("got %v, %v, want registry from mirror", reg, err)
+	}
+}
+
+func TestFailoverSource_CrossCheck(t *testing.T) {
+	primary := &countingSource{name: "primary", n: 50}
+	mirror := &countingSource{name: "mirror", n: 100}
+	spare := &countingSource{name: "spare", n: 1000}
+	f := NewFailoverSource(primary, mirror, spare)
+	f.CrossCheck = 0.2
+
+	reg, err := f.FetchRegistry(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+	if reg.Stats.Source != "mirror" {
+		t.Fatalf("source = %s, want mirror (primary is truncated)", reg.Stats.Source)
+	}
+	if spare.calls != 0 {
+		t.Fatalf("spare calls = %d, want 0: two sources are enough", spare.calls)
+	}
+
+	// Within tolerance the priority order wins.
+	primary.n = 90
+	reg, err = f.FetchRegistry(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+	if reg.Stats.Source != "primary" {
+		t.Fatalf("source = %s, want primary", reg.Stats.Source)
+	}
+}
+
+func TestFailoverSource_NotModifiedRefetched(t *testing.T) {
+	primary := &conditionalSource{countingSource: countingSource{name: "primary", n: 10}}
+	mirror := &conditionalSource{countingSource: countingSource{name: "mirror", n: 10}}
+	f := NewFailoverSource(primary, mirror)
+	ctx := context.Background()
+
+	if _, err := f.FetchRegistry(ctx); err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+	primary.err = errors.New("down")
+	if reg, err := f.FetchRegistry(ctx); err != nil || reg.Stats.Source != "mirror" {
+		t.Fatalf("got %v, %v, want registry from mirror", reg, err)
+	}
+
+	// The primary is back with the data it had before the outage: it
+	// answers 304, yet it must be promoted back.
+	primary.err = nil
+	reg, err := f.FetchRegistry(ctx)
+	if err != nil || reg.Stats.Source != "primary" {
+		t.Fatalf("got %v, %v, want registry from primary", reg, err)
+	}
+	if _, err := f.FetchRegistry(ctx); !errors.Is(err, ErrNotModified) {
+		t.Fatalf("err = %v, want ErrNotModified from the active primary", err)
+	}
+
+	// The primary changes and comes out truncated; the mirror, unchanged
+	// since the outage, answers 304 but is still compared with.
+	primary.valid, primary.n = false, 5
+	f.CrossCheck = 0.2
+	reg, err = f.FetchRegistry(ctx)
+	if err != nil || reg.Stats.Source != "mirror" {
+		t.Fatalf("got %v, %v, want registry from mirror (primary is truncated)", reg, err)
+	}
+}
+
+func TestFailoverSource_HangingPrimary(t *testing.T) {
+	hang := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hang.Close()
+	mirror := newTestAPI(t, map[string]string{
+		"/domains/": `["blocked.com"]`,
+		"/urls/":    `[]`,
+		"/ips/":     `[]`,
+	})
+
+	primary := NewClient(hang.URL)
+	primary.Timeout = 100 * time.Millisecond
+	holder := NewHolder()
+
+	res, err := updateOnce(context.Background(), NewFailoverSource(primary, NewClient(mirror.URL)), holder, nil)
+	if err != nil {
+		t.Fatalf("updateOnce error: %v", err)
+	}
+	if res.Stats.Source != mirror.URL || res.Domains != 1 {
+		t.Fatalf("got %d domains from %s, want 1 from the mirror", res.Domains, res.Stats.Source)
+	}
+}
@@ -0,0 +1,163 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"evil-rkn/internal/domain"
+)
+
+// FileSource reads the registry from a local file or a directory of files,
+// for air-gapped installations without access to the upstream API.
+//
+// The format of every file is picked by its name, files may be stored
+// pre-compressed as .gz, .zst or .br:
+//   - *.xml  — official RKN dump.xml;
+//   - *.csv  — zapret-info dump.csv;
+//   - *.json — JSON array of strings, the same as the rublacklist API returns.
+//     Files named urls*.json hold URLs, ips*.json hold IPs and subnets,
+//     anything else holds domains.
+//
+// In directory mode all files with a known format are merged into one
+// registry and the rest (editor backups, READMEs) are ignored.
+type FileSource struct {
+	path     string
+	debounce time.Duration
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{
+		path: path,
+		// Editors and rsync touch a file several times per save.
+		debounce: 500 * time.Millisecond,
+	}
+}
+
+// FetchRegistry implements the Fetcher interface.
+func (s *FileSource) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+	st, err := os.Stat(s.path)
+	if err != nil {
+		return nil, attemptFailed(domain.IngestStats{Source: s.path}, fmt.Errorf("file source: %w", err))
+	}
+
+	b := newBuilder(s.path)
+	if !st.IsDir() {
+		if err := loadFile(ctx, b, s.path); err != nil {
+			return nil, attemptFailed(b.stats, fmt.Errorf("file source: %w", err))
+		}
+	} else {
+		entries, err := os.ReadDir(s.path)
+		if err != nil {
+			return nil, attemptFailed(b.stats, fmt.Errorf("file source: %w", err))
+		}
+
+		var loaded int
+		for _, e := range entries {
+			if !e.Type().IsRegular() || fileParser(e.Name()) == nil {
+				continue
+			}
+			if err := loadFile(ctx, b, filepath.Join(s.path, e.Name())); err != nil {
+				return nil, attemptFailed(b.stats, fmt.Errorf("file source: %w", err))
+			}
+			loaded++
+		}
+		if loaded == 0 {
+			return nil, attemptFailed(b.stats, fmt.Errorf("file source: no registry files in %s", s.path))
+		}
+	}
+
+	reg := b.Build()
+	logBuild("file", reg)
+	return reg, nil
+}
+
+// Watch implements the Watcher interface: it signals after the file or
+// the directory content changes. Bursts of events are coalesced.
+func (s *FileSource) Watch(ctx context.Context) <-chan struct{} {
+	events, err := watchPath(ctx, s.path)
+	if err != nil {
+		log.Printf("file source: watch %s disabled: %v", s.path, err)
+		return nil
+	}
+	return debounce(ctx, events, s.debounce)
+}
+
+func loadFile(ctx context.Context, b *builder, path string) error {
+	parse := fileParser(filepath.Base(path))
+	if parse == nil {
+		return fmt.Errorf("%s: unknown registry format", path)
+	}
+
+	f, err := openLocation(ctx, path, &b.stats)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := parse(f, b); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// fileParser picks a parser by file name, nil if the format is unknown.
+// A compression extension is ignored: "domains.json.gz" is JSON.
+func fileParser(name string) func(io.Reader, *builder) error {
+	name, _ = splitCompressedExt(strings.ToLower(name))
+	switch filepath.Ext(name) {
+	case ".xml":
+		return parseDumpXML
+	case ".csv":
+		return parseDumpCSV
+	case ".json":
+		add := (*builder).AddDomain
+		switch {
+		case strings.HasPrefix(name, "urls"):
+			add = (*builder).AddURL
+		case strings.HasPrefix(name, "ips"):
+			add = (*builder).AddIP
+		}
+		return func(r io.Reader, b *builder) error {
+			return decodeJSONList(r, func(s string) { add(b, s) })
+		}
+	default:
+		return nil
+	}
+}
+
+// debounce forwards a signal once in has been quiet for d.
+func debounce(ctx context.Context, in <-chan struct{}, d time.Duration) <-chan struct{} {
+	out := make(chan struct{}, 1)
+
+	go func() {
+		timer := time.NewTimer(d)
+		timer.Stop()
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-in:
+				if !ok {
+					return
+				}
+				timer.Reset(d)
+			case <-timer.C:
+				select {
+				case out <- struct{}{}:
+				default:
+					// A reload is already pending.
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestFileSource_JSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "domains.json")
+	writeFile(t, path, `["blocked.com", "bad_domain.com"]`)
+
+	reg, err := NewFileSource(path).FetchRegistry(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+	checkBlocked(t, reg, map[string]bool{
+		"https://sub.blocked.com/": true,
+		"https://other.com/":       false,
+	})
+}
+
+func TestFileSource_Directory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "domains.json"), `["blocked.com"]`)
+	writeFile(t, filepath.Join(dir, "urls.json"), `["http://example.com/page"]`)
+	writeFile(t, filepath.Join(dir, "ips.json"), `["203.0.113.0/24"]`)
+	writeFile(t, filepath.Join(dir, "dump.csv"), "Updated: 2024-05-01 10:00:00 +0000\n;csv.example;;org;1;2024-01-01\n")
+	writeFile(t, filepath.Join(dir, "README.txt"), "not a registry")
+	writeFile(t, filepath.Join(dir, "domains.json~"), "garbage")
+
+	reg, err := NewFileSource(dir).FetchRegistry(context.Background())
+	if err != nil {
+		t.Fatalf("FetchRegistry error: %v", err)
+	}
+	checkBlocked(t, reg, map[string]bool{
+		"https://blocked.com/":     true,
+		"http://example.com/page":  true,
+		"http://203.0.113.9/":      true,
+		"https://csv.example/":     true,
+		"https://not-listed.com/":  false,
+		"http://example.com/other": false,
+	})
+}
+
+func TestFileSource_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := NewFileSource(dir).FetchRegistry(context.Background()); err == nil {
+		t.Error("expected error for an empty directory")
+	}
+	if _, err := NewFileSource(filepath.Join(dir, "missing.json")).FetchRegistry(context.Background()); err == nil {
+		t.Error("expected error for a missing file")
+	}
+
+	bad := filepath.Join(dir, "domains.json")
+	writeFile(t, bad, `{"not": "an array"}`)
+	if _, err := NewFileSource(bad).FetchRegistry(context.Background()); err == nil {
+		t.Error("expected error for a malformed file")
+	}
+}
+
+func TestFileSource_Watch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "domains.json")
+	writeFile(t, path, `["blocked.com"]`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := NewFileSource(path)
+	src.debounce = 20 * time.Millisecond
+	changes := src.Watch(ctx)
+	if changes == nil {
+		t.Fatal("Watch returned nil channel")
+	}
+
+	// Changes of unrelated files in the same directory are ignored.
+	writeFile(t, filepath.Join(dir, "other.json"), `[]`)
+	select {
+	case <-changes:
+		t.Fatal("unexpected change signal for an unrelated file")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// An atomic replace is noticed.
+	tmp := filepath.Join(dir, ".domains.json.tmp")
+	writeFile(t, tmp, `["blocked.com", "new.com"]`)
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change signal after file replace")
+	}
+}
+
+func TestNewSource(t *testing.T) {
+	if src, err := NewSource("file:///var/lib/rkn/domains.json"); err != nil {
+		t.Fatalf("NewSource(file) error: %v", err)
+	} else if fs, ok := src.(*FileSource); !ok || fs.path != "/var/lib/rkn/domains.json" {
+		t.Fatalf("NewSource(file) = %#v, want *FileSource", src)
+	}
+
+	if src, err := NewSource("https://reestr.rublacklist.net/api/v3"); err != nil {
+		t.Fatalf("NewSource(https) error: %v", err)
+	} else if _, ok := src.(*Client); !ok {
+		t.Fatalf("NewSource(https) = %#v, want *Client", src)
+	}
+
+	for spec, want := range map[string]string{
+		"https://example.org/dump.xml.gz":         "*registry.DumpXMLSource",
+		"https://example.org/dump.csv":            "*registry.DumpCSVSource",
+		"csv+https://example.org/latest":          "*registry.DumpCSVSource",
+		"xml+file:///var/lib/rkn/dump":            "*registry.DumpXMLSource",
+		"api+https://mirror.example.org/dump.xml": "*registry.Client",
+	} {
+		src, err := NewSource(spec)
+		if err != nil {
+			t.Fatalf("NewSource(%q) error: %v", spec, err)
+		}
+		if got := fmt.Sprintf("%T", src); got != want {
+			t.Errorf("NewSource(%q) = %s, want %s", spec, got, want)
+		}
+	}
+
+	for _, spec := range []string{"ftp://example.com/dump.xml", "json+https://example.org/x", "/var/lib/rkn"} {
+		if _, err := NewSource(spec); err == nil {
+			t.Errorf("NewSource(%q): expected error", spec)
+		}
+	}
+}
+
+func TestNewSources(t *testing.T) {
+	src, err := NewSources([]string{"https://reestr.rublacklist.net/api/v3"}, 0, 0)
+	if err != nil {
+		t.Fatalf("NewSources error: %v", err)
+	}
+	if _, ok := src.(*Client); !ok {
+		t.Fatalf("single source = %T, want *Client", src)
+	}
+
+	src, err = NewSources([]string{"https://reestr.rublacklist.net/api/v3", "file:///var/lib/rkn"}, 0.1, 0)
+	if err != nil {
+		t.Fatalf("NewSources error: %v", err)
+	}
+	if f, ok := src.(*FailoverSource); !ok || len(f.sources) != 2 || f.CrossCheck != 0.1 {
+		t.Fatalf("got %#v, want FailoverSource of 2", src)
+	}
+
+	src, err = NewSources([]string{"https://example.org/dump.xml"}, 0, time.Hour)
+	if err != nil {
+		t.Fatalf("NewSources error: %v", err)
+	}
+	if d, ok := src.(*DumpXMLSource); !ok || d.Timeout != time.Hour {
+		t.Fatalf("got %#v, want DumpXMLSource with a 1h timeout", src)
+	}
+
+	if _, err := NewSources(nil, 0, 0); err == nil {
+		t.Fatal("expected error for no sources")
+	}
+}
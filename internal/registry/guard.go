@@ -0,0 +1,163 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"evil-rkn/internal/domain"
+)
+
+// ErrRejected is returned by the updater when a fetched registry fails the
+// Guard rules. The registry is kept aside, see Guard.Rejected.
+var ErrRejected = errors.New("registry update rejected")
+
+// ErrNothingRejected is returned by Guard.ForceAccept when there is no
+// rejected update to accept.
+var ErrNothingRejected = errors.New("no rejected registry update")
+
+// Registry entry kinds, as used by GuardConfig.RequiredKinds.
+const (
+	KindDomains    = "domains"
+	KindURLs       = "urls"
+	KindHTTPSHosts = "https_hosts"
+	KindIPs        = "ips"
+)
+
+// GuardConfig holds the sanity rules a new registry must pass before it
+// replaces the current one. Zero values disable a rule.
+type GuardConfig struct {
+	// MaxDrop and MaxGrowth limit the relative change of every kind
+	// compared to the current registry, e.g. 0.5 rejects a list that lost
+	// half of its domains. Kinds that were empty are not compared.
+	MaxDrop   float64
+	MaxGrowth float64
+
+	// MinEntries is the minimum total number of entries.
+	MinEntries int
+
+	// RequiredKinds must all be non-empty, see the Kind constants.
+	RequiredKinds []string
+}
+
+// RejectedUpdate is a registry that failed the guard.
+type RejectedUpdate struct {
+	Registry *domain.Registry
+	Reasons  []string
+	At       time.Time
+}
+
+// Guard checks fetched registries against GuardConfig and keeps the last
+// rejected one until it is force-accepted or superseded. A nil *Guard
+// accepts everything.
+type Guard struct {
+	cfg GuardConfig
+
+	mu       sync.Mutex
+	rejected *RejectedUpdate
+}
+
+func NewGuard(cfg GuardConfig) (*Guard, error) {
+	known := kindCounts(&domain.Registry{})
+	for _, kind := range cfg.RequiredKinds {
+		if _, ok := known[kind]; !ok {
+			return nil, fmt.Errorf("unknown registry kind %q, want one of %s, %s, %s, %s",
+				kind, KindDomains, KindURLs, KindHTTPSHosts, KindIPs)
+		}
+	}
+	return &Guard{cfg: cfg}, nil
+}
+
+// check returns ErrRejected with the reasons if next must not replace cur.
+// A rejected registry replaces the previously rejected one; an accepted
+// registry clears it.
+func (g *Guard) check(cur, next *domain.Registry) error {
+	if g == nil {
+		return nil
+	}
+
+	reasons := g.cfg.violations(cur, next)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(reasons) == 0 {
+		g.rejected = nil
+		return nil
+	}
+	g.rejected = &RejectedUpdate{Registry: next, Reasons: reasons, At: time.Now().UTC()}
+	return fmt.Errorf("%w: %s", ErrRejected, strings.Join(reasons, "; "))
+}
+
+// Rejected returns the last rejected update, if any.
+func (g *Guard) Rejected() (RejectedUpdate, bool) {
+	if g == nil {
+		return RejectedUpdate{}, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.rejected == nil {
+		return RejectedUpdate{}, false
+	}
+	return *g.rejected, true
+}
+
+// ForceAccept swaps the last rejected registry into holder, overriding the
+// rules once. It is meant for operators who have checked that a big change
+// is legitimate.
+func (g *Guard) ForceAccept(holder *Holder) (*domain.Registry, error) {
+	if g == nil {
+		return nil, ErrNothingRejected
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.rejected == nil {
+		return nil, ErrNothingRejected
+	}
+	reg := g.rejected.Registry
+	g.rejected = nil
+	holder.Set(reg)
+	return reg, nil
+}
+
+func (c GuardConfig) violations(cur, next *domain.Registry) []string {
+	var reasons []string
+
+	if total := entryCount(next); total < c.MinEntries {
+		reasons = append(reasons, fmt.Sprintf("%d entries, want at least %d", total, c.MinEntries))
+	}
+
+	curCounts, nextCounts := kindCounts(cur), kindCounts(next)
+	for _, kind := range c.RequiredKinds {
+		if nextCounts[kind] == 0 {
+			reasons = append(reasons, fmt.Sprintf("no %s", kind))
+		}
+	}
+
+	for _, kind := range []string{KindDomains, KindURLs, KindHTTPSHosts, KindIPs} {
+		was, now := curCounts[kind], nextCounts[kind]
+		if was == 0 {
+			continue
+		}
+		change := float64(now-was) / float64(was)
+		switch {
+		case c.MaxDrop > 0 && -change > c.MaxDrop:
+			reasons = append(reasons, fmt.Sprintf("%s dropped by %.0f%% (%d -> %d), limit %.0f%%",
+				kind, -change*100, was, now, c.MaxDrop*100))
+		case c.MaxGrowth > 0 && change > c.MaxGrowth:
+			reasons = append(reasons, fmt.Sprintf("%s grew by %.0f%% (%d -> %d), limit %.0f%%",
+				kind, change*100, was, now, c.MaxGrowth*100))
+		}
+	}
+	return reasons
+}
+
+func kindCounts(reg *domain.Registry) map[string]int {
+	return map[string]int{
+		KindDomains:    len(reg.DomainHashes),
+		KindURLs:       len(reg.URLHashes),
+		KindHTTPSHosts: len(reg.URLHostHashes),
+		KindIPs:        reg.IPs.Len(),
+	}
+}
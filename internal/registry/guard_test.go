@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"evil-rkn/internal/domain"
+)
+
+// sizedRegistry returns a registry with the given number of domains and IPs.
+func sizedRegistry(domains, ips int) *domain.Registry {
+	reg := &domain.Registry{IPs: domain.NewIPSet()}
+	for i := 0; i < domains; i++ {
+		reg.DomainHashes = append(reg.DomainHashes, uint64(i))
+	}
+	for i := 0; i < ips; i++ {
+		reg.IPs.InsertAddr(netip.AddrFrom4([4]byte{10, 0, byte(i >> 8), byte(i)}))
+	}
+	return reg
+}
+
+func TestGuard_Rules(t *testing.T) {
+	cur := sizedRegistry(100, 10)
+
+	tests := []struct {
+		name   string
+		cfg    GuardConfig
+		next   *domain.Registry
+		reason string // substring of the rejection, empty if accepted
+	}{
+		{"within limits", GuardConfig{MaxDrop: 0.5, MaxGrowth: 1}, sizedRegistry(60, 15), ""},
+		{"drop", GuardConfig{MaxDrop: 0.5}, sizedRegistry(40, 10), "domains dropped by 60%"},
+		{"empty upstream", GuardConfig{MaxDrop: 0.5}, sizedRegistry(0, 0), "domains dropped by 100%"},
+		{"growth", GuardConfig{MaxGrowth: 1}, sizedRegistry(100, 25), "ips grew by 150%"},
+		{"min entries", GuardConfig{MinEntries: 200}, sizedRegistry(100, 10), "110 entries, want at least 200"},
+		{"required kind", GuardConfig{RequiredKinds: []string{KindIPs}}, sizedRegistry(100, 0), "no ips"},
+		{"rules disabled", GuardConfig{}, sizedRegistry(0, 0), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGuard(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewGuard error: %v", err)
+			}
+			err = g.check(cur, tt.next)
+			if tt.reason == "" {
+				if err != nil {
+					t.Fatalf("check() = %v, want accepted", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrRejected) || !strings.Contains(err.Error(), tt.reason) {
+				t.Fatalf("check() = %v, want rejection with %q", err, tt.reason)
+			}
+		})
+	}
+}
+
+func TestGuard_ColdStart(t *testing.T) {
+	g, _ := NewGuard(GuardConfig{MaxDrop: 0.5, MaxGrowth: 0.5})
+	if err := g.check(NewHolder().Get(), sizedRegistry(100, 10)); err != nil {
+		t.Fatalf("first registry must not be compared with the empty one: %v", err)
+	}
+}
+
+func TestNewGuard_UnknownKind(t *testing.T) {
+	if _, err := NewGuard(GuardConfig{RequiredKinds: []string{"subnets"}}); err == nil {
+		t.Fatal("expected error for unknown kind")
+	}
+}
+
+func TestUpdateOnce_RejectedAndForceAccept(t *testing.T) {
+	holder := NewHolder()
+	cur := sizedRegistry(100, 10)
+	holder.Set(cur)
+	guard, _ := NewGuard(GuardConfig{MaxDrop: 0.5})
+
+	truncated := sizedRegistry(3, 10)
+	_, err := updateOnce(context.Background(), &fakeSource{reg: truncated}, holder, guard)
+	if !errors.Is(err, ErrRejected) {
+		t.Fatalf("updateOnce error = %v, want ErrRejected", err)
+	}
+	if holder.Get() != cur {
+		t.Fatal("rejected registry must not be swapped in")
+	}
+
+	rej, ok := guard.Rejected()
+	if !ok || rej.Registry != truncated || len(rej.Reasons) != 1 {
+		t.Fatalf("Rejected() = %+v, %v, want the truncated registry", rej, ok)
+	}
+
+	if _, err := guard.ForceAccept(holder); err != nil {
+		t.Fatalf("ForceAccept error: %v", err)
+	}
+	if holder.Get() != truncated {
+		t.Fatal("force-accepted registry must be in the holder")
+	}
+	if _, ok := guard.Rejected(); ok {
+		t.Fatal("rejected update must be cleared after ForceAccept")
+	}
+	if _, err := guard.ForceAccept(holder); err == nil {
+		t.Fatal("expected error with nothing to accept")
+	}
+}
@@ -1,13 +1,25 @@
 package registry
 
 import (
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"evil-rkn/internal/domain"
 )
 
+// diffHistorySize is the number of diffs a Holder keeps.
+const diffHistorySize = 64
+
+// Holder publishes the current registry to readers without locking and
+// numbers its versions: every Set is a new version, and the diff against
+// the previous one is kept in a bounded history.
 type Holder struct {
 	value atomic.Pointer[domain.Registry]
+
+	mu      sync.Mutex // serializes writers
+	history []Diff     // oldest first, at most diffHistorySize
+	subs    map[chan struct{}]struct{}
 }
 
 func NewHolder() *Holder {
@@ -15,8 +27,9 @@ func NewHolder() *Holder {
 	empty := &domain.Registry{
 		DomainHashes: nil,
 		URLHashes:    nil,
-		IPs:          make(map[string]struct{}),
+		IPs:          domain.NewIPSet(),
 	}
+	empty.Fingerprint = domain.ContentFingerprint(empty)
 	h.value.Store(empty)
 	return h
 }
@@ -25,6 +38,87 @@ func (h *Holder) Get() *domain.Registry {
 	return h.value.Load()
 }
 
+// Set publishes reg as the next version and records the diff against the
+// current one. reg.Version is assigned unless it already carries a newer
+// version, as a registry restored from a snapshot does, and
+// reg.Fingerprint is computed. The caller must not modify reg afterwards.
 func (h *Holder) Set(reg *domain.Registry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev := h.value.Load()
+	if reg.Version <= prev.Version {
+		reg.Version = prev.Version + 1
+	}
+	reg.Fingerprint = domain.ContentFingerprint(reg)
+
+	d := computeDiff(prev, reg)
+	d.At = time.Now().UTC()
+	if len(h.history) == diffHistorySize {
+		h.history = append(h.history[:0:0], h.history[1:]...)
+	}
+	h.history = append(h.history, d)
+
 	h.value.Store(reg)
+
+	for ch := range h.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// A signal is already pending: the subscriber will catch up
+			// on all versions at once from Diffs.
+		}
+	}
+}
+
+// Subscribe returns a channel signalled after every version change and
+// a function to unsubscribe. Signals are coalesced, so a slow subscriber
+// never blocks Set; it should compare versions and read what it missed
+// from Diffs, which covers the last diffHistorySize versions.
+func (h *Holder) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[chan struct{}]struct{})
+	}
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs, ch)
+		h.mu.Unlock()
+	}
+}
+
+// Touch marks the current registry as confirmed fresh at t without
+// changing its content or version. Registries are never mutated, so a
+// copy is published.
+func (h *Holder) Touch(t time.Time) *domain.Registry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fresh := *h.value.Load()
+	fresh.LastUpdated = t
+	h.value.Store(&fresh)
+	return &fresh
+}
+
+// Diffs returns up to limit most recent diffs to versions newer than
+// since, oldest first. limit <= 0 means all kept.
+func (h *Holder) Diffs(since uint64, limit int) []Diff {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var out []Diff
+	for _, d := range h.history {
+		if d.ToVersion > since {
+			out = append(out, d)
+		}
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
 }
@@ -3,6 +3,7 @@ package registry
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"evil-rkn/internal/domain"
 )
@@ -20,7 +21,7 @@ func TestHolder_GetSet(t *testing.T) {
 	reg := &domain.Registry{
 		DomainHashes: []uint64{hash},
 		URLHashes:    nil,
-		IPs:          make(map[string]struct{}),
+		IPs:          domain.NewIPSet(),
 	}
 
 	h.Set(reg)
@@ -43,7 +44,7 @@ func TestHolder_ConcurrentAccess(t *testing.T) {
 			reg := &domain.Registry{
 				DomainHashes: []uint64{domain.HashString64("example.com")},
 				URLHashes:    nil,
-				IPs:          make(map[string]struct{}),
+				IPs:          domain.NewIPSet(),
 			}
 			h.Set(reg)
 		}
@@ -62,3 +63,90 @@ func TestHolder_ConcurrentAccess(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestHolder_Versions(t *testing.T) {
+	h := NewHolder()
+	if v := h.Get().Version; v != 0 {
+		t.Fatalf("initial Version = %d, want 0", v)
+	}
+
+	h.Set(buildRegistry([]string{"a.com"}, nil, nil))
+	h.Set(buildRegistry([]string{"a.com", "b.com"}, nil, nil))
+	if v := h.Get().Version; v != 2 {
+		t.Fatalf("Version = %d, want 2", v)
+	}
+
+	// A restored registry keeps its newer version.
+	restored := buildRegistry([]string{"b.com"}, nil, nil)
+	restored.Version = 40
+	h.Set(restored)
+	if v := h.Get().Version; v != 40 {
+		t.Fatalf("Version = %d, want 40", v)
+	}
+
+	touched := h.Touch(time.Now())
+	if touched.Version != 40 || touched == restored || len(h.Diffs(0, 0)) != 3 {
+		t.Fatal("Touch must keep the version and record no diff")
+	}
+
+	diffs := h.Diffs(1, 0)
+	if len(diffs) != 2 || diffs[0].FromVersion != 1 || diffs[0].ToVersion != 2 || diffs[1].ToVersion != 40 {
+		t.Fatalf("Diffs(1) = %+v, want 1->2 and 2->40", diffs)
+	}
+	if got := diffs[0].Domains.Added; len(got) != 1 || got[0] != "b.com" {
+		t.Fatalf("1->2 added %v, want [b.com]", got)
+	}
+	if got := h.Diffs(0, 1); len(got) != 1 || got[0].ToVersion != 40 {
+		t.Fatalf("Diffs(0, 1) = %+v, want the latest", got)
+	}
+}
+
+func TestHolder_HistoryBounded(t *testing.T) {
+	h := NewHolder()
+	for i := 0; i < diffHistorySize+5; i++ {
+		h.Set(&domain.Registry{DomainHashes: []uint64{uint64(i)}})
+	}
+
+	diffs := h.Diffs(0, 0)
+	if len(diffs) != diffHistorySize {
+		t.Fatalf("kept %d diffs, want %d", len(diffs), diffHistorySize)
+	}
+	if last := diffs[len(diffs)-1].ToVersion; last != h.Get().Version {
+		t.Fatalf("last diff to %d, want current version %d", last, h.Get().Version)
+	}
+}
+
+func TestHolder_Subscribe(t *testing.T) {
+	h := NewHolder()
+	changes, unsubscribe := h.Subscribe()
+
+	// A subscriber that does not read never blocks Set, signals coalesce.
+	for i := 0; i < 10; i++ {
+		h.Set(&domain.Registry{})
+	}
+	select {
+	case <-changes:
+	default:
+		t.Fatal("expected a pending signal")
+	}
+	select {
+	case <-changes:
+		t.Fatal("signals must coalesce")
+	default:
+	}
+
+	h.Touch(time.Now())
+	select {
+	case <-changes:
+		t.Fatal("Touch is not a version change")
+	default:
+	}
+
+	unsubscribe()
+	h.Set(&domain.Registry{})
+	select {
+	case <-changes:
+		t.Fatal("no signal expected after unsubscribe")
+	default:
+	}
+}
@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"sync"
+	"time"
+)
+
+// reportHistorySize is the number of update reports a Monitor keeps.
+const reportHistorySize = 32
+
+// Status is the updater's schedule as seen by operators.
+type Status struct {
+	NextRun     time.Time // zero until the updater starts
+	Attempts    int       // consecutive failed attempts, 0 after a success
+	LastAttempt time.Time
+	LastSuccess time.Time
+	LastError   error // of the last attempt, nil if it succeeded
+}
+
+// UpdateReport describes one update attempt.
+type UpdateReport struct {
+	Trigger    string // what started it, e.g. "update" or "requested update"
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	// Result of the attempt. If it failed, only Stats may be set: for a
+	// registry that was fetched but rejected by the Guard.
+	Result UpdateResult
+	Err    error
+}
+
+// Duration returns how long the attempt took.
+func (r UpdateReport) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Monitor records what the Start loop does. Pass it in Config; a nil
+// Monitor records nothing.
+type Monitor struct {
+	mu      sync.Mutex
+	status  Status
+	reports []UpdateReport // oldest first, at most reportHistorySize
+}
+
+func NewMonitor() *Monitor {
+	return &Monitor{}
+}
+
+// Status returns the current schedule.
+func (m *Monitor) Status() Status {
+	if m == nil {
+		return Status{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// Reports returns at most limit most recent reports, oldest first.
+// A limit <= 0 returns all kept.
+func (m *Monitor) Reports(limit int) []UpdateReport {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	reports := m.reports
+	if limit > 0 && len(reports) > limit {
+		reports = reports[len(reports)-limit:]
+	}
+	return append([]UpdateReport(nil), reports...)
+}
+
+func (m *Monitor) scheduled(next time.Time) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.NextRun = next
+}
+
+// record adds a report; attempts is the number of consecutive failures
+// including this attempt.
+func (m *Monitor) record(rep UpdateReport, attempts int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.LastAttempt = rep.FinishedAt
+	m.status.Attempts = attempts
+	m.status.LastError = rep.Err
+	if rep.Err == nil {
+		m.status.LastSuccess = rep.FinishedAt
+	}
+	if len(m.reports) == reportHistorySize {
+		m.reports = append(m.reports[:0:0], m.reports[1:]...)
+	}
+	m.reports = append(m.reports, rep)
+}
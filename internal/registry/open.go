@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"evil-rkn/internal/domain"
+)
+
+// DefaultDumpTimeout bounds a fetch of a dump source unless configured
+// otherwise: downloading and parsing a multi-hundred-megabyte dump.xml
+// takes minutes on an ordinary link.
+const DefaultDumpTimeout = 30 * time.Minute
+
+// sourceHTTP downloads registry dumps. There is no client timeout on
+// purpose: dumps are large and every dump source bounds its fetch with
+// its own Timeout.
+var sourceHTTP = &http.Client{}
+
+// openLocation opens a registry dump by location: an http(s) URL,
+// a file:// URL or a plain filesystem path. The result is already
+// decompressed, whether the server used a Content-Encoding or the file
+// is stored as .gz/.zst/.br. Transferred and decoded sizes go to st.
+func openLocation(ctx context.Context, location string, st *domain.IngestStats) (io.ReadCloser, error) {
+	raw, encoding, err := openRaw(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	dec, err := decodeStream(raw, encoding, st)
+	if err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("%s: %w", location, err)
+	}
+	return &closeBoth{ReadCloser: dec, raw: raw}, nil
+}
+
+func openRaw(ctx context.Context, location string) (io.ReadCloser, string, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("create request: %w", err)
+		}
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+
+		resp, err := sourceHTTP.Do(req)
+		if err != nil {
+			return nil, "", fmt.Errorf("do request: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, "", fmt.Errorf("unexpected status: %s", resp.Status)
+		}
+		return resp.Body, resp.Header.Get("Content-Encoding"), nil
+	}
+
+	path := strings.TrimPrefix(location, "file://")
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	_, encoding := splitCompressedExt(path)
+	return f, encoding, nil
+}
+
+// closeBoth closes the decoder and then the underlying stream.
+type closeBoth struct {
+	io.ReadCloser
+	raw io.Closer
+}
+
+func (c *closeBoth) Close() error {
+	err := c.ReadCloser.Close()
+	if rerr := c.raw.Close(); err == nil {
+		err = rerr
+	}
+	return err
+}
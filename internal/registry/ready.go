@@ -0,0 +1,142 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReadinessPolicy decides when the served registry is fit to answer
+// checks. Zero values disable a rule; the registry must always have been
+// loaded, from the upstream or from a snapshot.
+type ReadinessPolicy struct {
+	// MaxAge is how long after its last confirmed update the registry
+	// stays ready.
+	MaxAge time.Duration
+
+	// MinEntries is the minimum number of entries per kind, see the Kind
+	// constants.
+	MinEntries map[string]int
+
+	// RequireFetch keeps the service unready until an update in this
+	// process succeeded. AllowSnapshot exempts a registry restored from a
+	// snapshot, so a restart during an upstream outage keeps serving.
+	RequireFetch  bool
+	AllowSnapshot bool
+}
+
+// DefaultReadinessPolicy returns the rules /readyz has always applied:
+// domains present and confirmed within 48 hours.
+func DefaultReadinessPolicy() ReadinessPolicy {
+	return ReadinessPolicy{
+		MaxAge:        48 * time.Hour,
+		MinEntries:    map[string]int{KindDomains: 1},
+		RequireFetch:  true,
+		AllowSnapshot: true,
+	}
+}
+
+// Criterion is one readiness rule with what was observed and required.
+type Criterion struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Value string `json:"value"`
+	Want  string `json:"want"`
+}
+
+// Readiness is the outcome of every rule of a ReadinessPolicy.
+type Readiness struct {
+	Ready    bool        `json:"ready"`
+	Criteria []Criterion `json:"criteria"`
+}
+
+// Err lists the failed criteria, nil if ready.
+func (r Readiness) Err() error {
+	var failed []string
+	for _, c := range r.Criteria {
+		if !c.OK {
+			failed = append(failed, fmt.Sprintf("%s is %s, want %s", c.Name, c.Value, c.Want))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.New("not ready: " + strings.Join(failed, "; "))
+}
+
+// ReadyChecker applies a ReadinessPolicy to the registry a Holder serves.
+// Both the HTTP /readyz probe and gRPC health checks follow it.
+type ReadyChecker struct {
+	policy  ReadinessPolicy
+	holder  *Holder
+	monitor *Monitor
+}
+
+// NewReadyChecker checks policy against holder. The monitor tells whether
+// an update succeeded; without one RequireFetch is only met by a snapshot.
+func NewReadyChecker(policy ReadinessPolicy, holder *Holder, monitor *Monitor) (*ReadyChecker, error) {
+	for kind, n := range policy.MinEntries {
+		if _, ok := kindCounts(holder.Get())[kind]; !ok {
+			return nil, fmt.Errorf("unknown registry kind %q, want one of %s, %s, %s, %s",
+				kind, KindDomains, KindURLs, KindHTTPSHosts, KindIPs)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("negative minimum for %s: %d", kind, n)
+		}
+	}
+	return &ReadyChecker{policy: policy, holder: holder, monitor: monitor}, nil
+}
+
+// Check evaluates every rule at now.
+func (c *ReadyChecker) Check(now time.Time) Readiness {
+	reg := c.holder.Get()
+	r := Readiness{Ready: true}
+	add := func(name string, ok bool, value, want string) {
+		r.Criteria = append(r.Criteria, Criterion{Name: name, OK: ok, Value: value, Want: want})
+		r.Ready = r.Ready && ok
+	}
+
+	loaded := !reg.LastUpdated.IsZero()
+	add("loaded", loaded, strconv.FormatBool(loaded), "true")
+
+	if c.policy.RequireFetch {
+		// Until the first success the holder can only have been filled
+		// from a snapshot.
+		fetched := !c.monitor.Status().LastSuccess.IsZero()
+		switch {
+		case fetched:
+			add("fetched", true, "true", fetchWant(c.policy))
+		case loaded && c.policy.AllowSnapshot:
+			add("fetched", true, "snapshot", fetchWant(c.policy))
+		default:
+			add("fetched", false, "false", fetchWant(c.policy))
+		}
+	}
+
+	if c.policy.MaxAge > 0 {
+		if !loaded {
+			add("age", false, "never updated", "<= "+c.policy.MaxAge.String())
+		} else {
+			age := now.Sub(reg.LastUpdated)
+			add("age", age >= 0 && age <= c.policy.MaxAge, age.Round(time.Second).String(), "<= "+c.policy.MaxAge.String())
+		}
+	}
+
+	counts := kindCounts(reg)
+	for _, kind := range []string{KindDomains, KindURLs, KindHTTPSHosts, KindIPs} {
+		if min := c.policy.MinEntries[kind]; min > 0 {
+			add(kind, counts[kind] >= min, strconv.Itoa(counts[kind]), ">= "+strconv.Itoa(min))
+		}
+	}
+
+	return r
+}
+
+func fetchWant(p ReadinessPolicy) string {
+	if p.AllowSnapshot {
+		return "true or snapshot"
+	}
+	return "true"
+}
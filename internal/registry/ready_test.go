@@ -0,0 +1,69 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"evil-rkn/internal/domain"
+)
+
+func TestReadyChecker(t *testing.T) {
+	now := time.Now()
+	fresh := &domain.Registry{DomainHashes: []uint64{1}, IPs: domain.NewIPSet(), LastUpdated: now.Add(-time.Hour)}
+
+	failed := func(r Readiness) []string {
+		var names []string
+		for _, c := range r.Criteria {
+			if !c.OK {
+				names = append(names, c.Name)
+			}
+		}
+		return names
+	}
+
+	cases := []struct {
+		name    string
+		policy  ReadinessPolicy
+		reg     *domain.Registry
+		fetched bool
+		want    []string // failed criteria
+	}{
+		{"default fresh", DefaultReadinessPolicy(), fresh, true, nil},
+		{"default empty holder", DefaultReadinessPolicy(), nil, false, []string{"loaded", "fetched", "age", KindDomains}},
+		{"snapshot allowed", DefaultReadinessPolicy(), fresh, false, nil},
+		{"snapshot not allowed", ReadinessPolicy{RequireFetch: true}, fresh, false, []string{"fetched"}},
+		{"too old", ReadinessPolicy{MaxAge: 30 * time.Minute}, fresh, true, []string{"age"}},
+		{"min ips", ReadinessPolicy{MinEntries: map[string]int{KindIPs: 1}}, fresh, true, []string{KindIPs}},
+	}
+	for _, tc := range cases {
+		holder := NewHolder()
+		if tc.reg != nil {
+			holder.Set(tc.reg)
+		}
+		mon := NewMonitor()
+		if tc.fetched {
+			mon.record(UpdateReport{FinishedAt: now}, 0)
+		}
+
+		c, err := NewReadyChecker(tc.policy, holder, mon)
+		if err != nil {
+			t.Fatalf("%s: NewReadyChecker error: %v", tc.name, err)
+		}
+		r := c.Check(now)
+		got := failed(r)
+		if len(got) != len(tc.want) || r.Ready != (len(tc.want) == 0) || (r.Err() == nil) != r.Ready {
+			t.Errorf("%s: failed %v (ready %v, err %v), want %v", tc.name, got, r.Ready, r.Err(), tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: failed %v, want %v", tc.name, got, tc.want)
+				break
+			}
+		}
+	}
+
+	if _, err := NewReadyChecker(ReadinessPolicy{MinEntries: map[string]int{"hosts": 1}}, NewHolder(), nil); err == nil {
+		t.Fatal("NewReadyChecker accepted an unknown kind")
+	}
+}
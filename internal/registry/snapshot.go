@@ -0,0 +1,329 @@
+package registry
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"time"
+
+	"evil-rkn/internal/domain"
+)
+
+// Snapshot file layout, all integers little-endian:
+//
+//	magic "RKNSNAP\x00", version uint32
+//	last updated (unix nanoseconds) int64, registry version uint64
+//	stats: uvarint length + JSON
+//	3 × key section (domains, urls, https hosts):
+//	    count uvarint, count × hash uint64,
+//	    keys count uvarint (0 or count), keys × (uvarint length + bytes)
+//	ips: count uvarint, count × (family byte 4 or 6, bits byte, 4 or 16 address bytes)
+//	CRC-32C of everything above, uint32
+//
+// The version is bumped on any incompatible change; snapshots of other
+// versions are rejected and the service simply starts cold.
+const (
+	snapshotMagic   = "RKNSNAP\x00"
+	snapshotVersion = 2
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// SaveSnapshot writes reg to path atomically: the data goes to a temporary
+// file in the same directory which is synced and renamed over path, so a
+// crash leaves either the old snapshot or the new one, never a torn file.
+func SaveSnapshot(path string, reg *domain.Registry) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	bw := bufio.NewWriterSize(tmp, 1<<20)
+	if err := writeSnapshot(bw, reg); err != nil {
+		tmp.Close()
+		return fmt.Errorf("snapshot: write %s: %w", tmp.Name(), err)
+	}
+	if err := bw.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("snapshot: write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("snapshot: sync %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+
+	// Make the rename itself durable.
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// LoadSnapshot reads a registry saved by SaveSnapshot. A missing file is
+// reported as an error wrapping fs.ErrNotExist.
+func LoadSnapshot(path string) (*domain.Registry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %w", err)
+	}
+	defer f.Close()
+
+	reg, err := readSnapshot(bufio.NewReaderSize(f, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: %s: %w", path, err)
+	}
+	return reg, nil
+}
+
+// snapshotWriter accumulates the first write error so the encoder reads
+// straight, and checksums everything written.
+type snapshotWriter struct {
+	w   io.Writer
+	crc hash.Hash32
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (w *snapshotWriter) write(p []byte) {
+	if w.err != nil {
+		return
+	}
+	w.crc.Write(p)
+	_, w.err = w.w.Write(p)
+}
+
+func (w *snapshotWriter) uvarint(v uint64) {
+	w.write(w.buf[:binary.PutUvarint(w.buf[:], v)])
+}
+
+func (w *snapshotWriter) uint64(v uint64) {
+	w.write(binary.LittleEndian.AppendUint64(w.buf[:0], v))
+}
+
+func (w *snapshotWriter) bytes(p []byte) {
+	w.uvarint(uint64(len(p)))
+	w.write(p)
+}
+
+func (w *snapshotWriter) keys(hs []uint64, keys *domain.KeyTable) {
+	w.uvarint(uint64(len(hs)))
+	for _, h := range hs {
+		w.uint64(h)
+	}
+
+	// Keys are stored only when they are aligned with the hashes,
+	// otherwise the lookup would not use them anyway.
+	if keys.Len() != len(hs) {
+		w.uvarint(0)
+		return
+	}
+	w.uvarint(uint64(keys.Len()))
+	for i := 0; i < keys.Len(); i++ {
+		w.bytes([]byte(keys.At(i)))
+	}
+}
+
+func writeSnapshot(out io.Writer, reg *domain.Registry) error {
+	stats, err := json.Marshal(reg.Stats)
+	if err != nil {
+		return err
+	}
+
+	w := &snapshotWriter{w: out, crc: crc32.New(crcTable)}
+	w.write([]byte(snapshotMagic))
+	w.write(binary.LittleEndian.AppendUint32(nil, snapshotVersion))
+	var updated int64 // the zero time has no UnixNano
+	if !reg.LastUpdated.IsZero() {
+		updated = reg.LastUpdated.UnixNano()
+	}
+	w.uint64(uint64(updated))
+	w.uint64(reg.Version)
+	w.bytes(stats)
+
+	w.keys(reg.DomainHashes, reg.DomainKeys)
+	w.keys(reg.URLHashes, reg.URLKeys)
+	w.keys(reg.URLHostHashes, reg.URLHostKeys)
+
+	w.uvarint(uint64(reg.IPs.Len()))
+	for p := range reg.IPs.All() {
+		family := byte(6)
+		if p.Addr().Is4() {
+			family = 4
+		}
+		w.write([]byte{family, byte(p.Bits())})
+		w.write(p.Addr().AsSlice())
+	}
+
+	if w.err != nil {
+		return w.err
+	}
+	_, err = out.Write(binary.LittleEndian.AppendUint32(nil, w.crc.Sum32()))
+	return err
+}
+
+// snapshotReader mirrors snapshotWriter.
+type snapshotReader struct {
+	r   *bufio.Reader
+	crc hash.Hash32
+	err error
+}
+
+func (r *snapshotReader) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == nil {
+		r.crc.Write([]byte{b})
+	}
+	return b, err
+}
+
+func (r *snapshotReader) read(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	p := make([]byte, n)
+	if _, r.err = io.ReadFull(r.r, p); r.err != nil {
+		return nil
+	}
+	r.crc.Write(p)
+	return p
+}
+
+func (r *snapshotReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r)
+	r.err = err
+	return v
+}
+
+// count reads an element count and rejects values a corrupted file could
+// use to make us allocate gigabytes.
+func (r *snapshotReader) count(max uint64) int {
+	n := r.uvarint()
+	if r.err == nil && n > max {
+		r.err = fmt.Errorf("implausible count %d", n)
+	}
+	return int(n)
+}
+
+func (r *snapshotReader) uint64() uint64 {
+	p := r.read(8)
+	if p == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(p)
+}
+
+func (r *snapshotReader) keys() ([]uint64, *domain.KeyTable) {
+	n := r.count(1 << 32)
+	if r.err != nil {
+		return nil, nil
+	}
+	// Capacity is capped: a bogus count hits EOF instead of OOM.
+	hs := make([]uint64, 0, min(n, 1<<20))
+	for i := 0; i < n && r.err == nil; i++ {
+		hs = append(hs, r.uint64())
+	}
+
+	nk := r.count(uint64(n))
+	if r.err != nil || nk == 0 {
+		return hs, nil
+	}
+	if nk != n {
+		r.err = fmt.Errorf("%d keys for %d hashes", nk, n)
+		return nil, nil
+	}
+	keys := make([]string, 0, min(nk, 1<<20))
+	for i := 0; i < nk && r.err == nil; i++ {
+		keys = append(keys, string(r.read(r.count(1<<16))))
+	}
+	return hs, domain.NewKeyTable(keys)
+}
+
+func readSnapshot(in *bufio.Reader) (*domain.Registry, error) {
+	r := &snapshotReader{r: in, crc: crc32.New(crcTable)}
+
+	if magic := r.read(len(snapshotMagic)); r.err != nil || string(magic) != snapshotMagic {
+		return nil, errors.New("not a registry snapshot")
+	}
+	version := r.read(4)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if v := binary.LittleEndian.Uint32(version); v != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d, want %d", v, snapshotVersion)
+	}
+
+	reg := &domain.Registry{IPs: domain.NewIPSet()}
+	if ns := int64(r.uint64()); ns != 0 {
+		reg.LastUpdated = time.Unix(0, ns).UTC()
+	}
+	reg.Version = r.uint64()
+	stats := r.read(r.count(1 << 24))
+	if r.err == nil {
+		if err := json.Unmarshal(stats, &reg.Stats); err != nil {
+			return nil, fmt.Errorf("stats: %w", err)
+		}
+	}
+
+	reg.DomainHashes, reg.DomainKeys = r.keys()
+	reg.URLHashes, reg.URLKeys = r.keys()
+	reg.URLHostHashes, reg.URLHostKeys = r.keys()
+
+	ips := r.count(1 << 32)
+	for i := 0; i < ips && r.err == nil; i++ {
+		head := r.read(2)
+		if head == nil {
+			break
+		}
+		var size int
+		switch head[0] {
+		case 4:
+			size = 4
+		case 6:
+			size = 16
+		default:
+			r.err = fmt.Errorf("bad address family %d", head[0])
+		}
+		addr, ok := netip.AddrFromSlice(r.read(size))
+		if !ok {
+			break
+		}
+		reg.IPs.Insert(netip.PrefixFrom(addr, int(head[1])))
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	want := r.crc.Sum32()
+	var sum [4]byte
+	if _, err := io.ReadFull(in, sum[:]); err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(sum[:]) != want {
+		return nil, errors.New("checksum mismatch")
+	}
+	return reg, nil
+}
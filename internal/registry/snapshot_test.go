@@ -0,0 +1,139 @@
+package registry
+
+import (
+	"errors"
+	"io/fs"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"evil-rkn/internal/domain"
+)
+
+func snapshotRegistry() *domain.Registry {
+	b := newBuilder("https://example.org/dump.xml")
+	b.AddDomain("example.com")
+	b.AddDomain("blocked.example.org")
+	b.AddURL("http://example.net/path?q=1")
+	b.AddURL("https://secure.example.net/page")
+	b.AddIP("192.0.2.1")
+	b.AddIP("198.51.100.0/24")
+	b.AddIP("2001:db8::/16") // shorter than /32, must stay IPv6
+	b.AddIP("2001:db8::1")
+	reg := b.Build()
+	reg.LastUpdated = time.Date(2024, 5, 1, 10, 0, 0, 123, time.UTC)
+	reg.Version = 42
+	return reg
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "registry.snap")
+	want := snapshotRegistry()
+
+	if err := SaveSnapshot(path, want); err != nil {
+		t.Fatalf("SaveSnapshot error: %v", err)
+	}
+	got, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got.DomainHashes, want.DomainHashes) ||
+		!reflect.DeepEqual(got.URLHashes, want.URLHashes) ||
+		!reflect.DeepEqual(got.URLHostHashes, want.URLHostHashes) {
+		t.Fatal("hashes differ after round trip")
+	}
+	if got.DomainKeys.Len() != want.DomainKeys.Len() || got.DomainKeys.At(0) != want.DomainKeys.At(0) {
+		t.Fatal("domain keys differ after round trip")
+	}
+	if !got.LastUpdated.Equal(want.LastUpdated) {
+		t.Fatalf("LastUpdated = %v, want %v", got.LastUpdated, want.LastUpdated)
+	}
+	if got.Version != want.Version {
+		t.Fatalf("Version = %d, want %d", got.Version, want.Version)
+	}
+	if !reflect.DeepEqual(got.Stats, want.Stats) {
+		t.Fatalf("Stats = %+v, want %+v", got.Stats, want.Stats)
+	}
+	if got.IPs.Len() != want.IPs.Len() {
+		t.Fatalf("IPs.Len() = %d, want %d", got.IPs.Len(), want.IPs.Len())
+	}
+	for _, a := range []string{"192.0.2.1", "198.51.100.7", "2001:db8:ffff::1"} {
+		if !got.IPs.Contains(netip.MustParseAddr(a)) {
+			t.Errorf("restored IPs must contain %s", a)
+		}
+	}
+	if got.IPs.Contains(netip.MustParseAddr("32.1.0.1")) {
+		t.Error("IPv6 /16 restored as IPv4")
+	}
+
+	for _, u := range []string{"http://example.net/path?q=1", "https://secure.example.net/", "http://blocked.example.org/"} {
+		n, err := domain.Normalize(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !domain.IsBlocked(got, n).Blocked {
+			t.Errorf("%s must be blocked by the restored registry", u)
+		}
+	}
+
+	// The temporary file must not be left behind.
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("snapshot dir has %d entries, want 1", len(entries))
+	}
+}
+
+func TestSnapshot_Empty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.snap")
+	if err := SaveSnapshot(path, NewHolder().Get()); err != nil {
+		t.Fatalf("SaveSnapshot error: %v", err)
+	}
+	got, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot error: %v", err)
+	}
+	if !got.LastUpdated.IsZero() || len(got.DomainHashes) != 0 || got.IPs.Len() != 0 {
+		t.Fatalf("got %+v, want empty registry", got)
+	}
+}
+
+func TestSnapshot_Corrupted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "registry.snap")
+	if err := SaveSnapshot(path, snapshotRegistry()); err != nil {
+		t.Fatalf("SaveSnapshot error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flipped := append([]byte(nil), data...)
+	flipped[len(flipped)/2] ^= 0xff
+	version := append([]byte(nil), data...)
+	version[len(snapshotMagic)] = 99
+
+	for name, content := range map[string][]byte{
+		"flipped byte": flipped,
+		"truncated":    data[:len(data)-10],
+		"version":      version,
+		"not snapshot": []byte("[\"example.com\"]"),
+		"empty":        nil,
+	} {
+		p := filepath.Join(dir, "bad.snap")
+		if err := os.WriteFile(p, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSnapshot(p); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := LoadSnapshot(filepath.Join(dir, "missing.snap")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("err = %v, want fs.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// NewSource creates a Fetcher from a source spec:
+//   - file:///var/lib/rkn/domains.json — a local file or directory, see FileSource;
+//   - https://example.org/dump.xml — an official dump.xml, see DumpXMLSource;
+//   - https://example.org/dump.csv — a zapret-info dump.csv, see DumpCSVSource;
+//   - https://reestr.rublacklist.net/api/v3 — the rublacklist API, see Client.
+//
+// For URLs whose name does not tell the format, prefix the spec with
+// "xml+", "csv+" or "api+", e.g. "csv+https://example.org/latest".
+// A prefixed file:// spec reads a single dump without watching it.
+func NewSource(spec string) (Fetcher, error) {
+	format, location := "", spec
+	if i := strings.Index(spec, "+"); i != -1 && !strings.Contains(spec[:i], "/") {
+		format, location = spec[:i], spec[i+1:]
+	}
+
+	switch {
+	case strings.HasPrefix(location, "file://"):
+		if format == "" {
+			return NewFileSource(strings.TrimPrefix(location, "file://")), nil
+		}
+	case strings.HasPrefix(location, "http://"), strings.HasPrefix(location, "https://"):
+	default:
+		return nil, fmt.Errorf("unsupported registry source %q", spec)
+	}
+
+	if format == "" {
+		name, _ := splitCompressedExt(path.Base(location))
+		switch path.Ext(strings.ToLower(name)) {
+		case ".xml":
+			format = "xml"
+		case ".csv":
+			format = "csv"
+		default:
+			format = "api"
+		}
+	}
+
+	switch format {
+	case "xml":
+		return NewDumpXMLSource(location), nil
+	case "csv":
+		return NewDumpCSVSource(location), nil
+	case "api":
+		return NewClient(location), nil
+	default:
+		return nil, fmt.Errorf("unsupported registry source format %q in %q", format, spec)
+	}
+}
+
+// NewSources creates a Fetcher for an ordered list of source specs.
+// A single source is returned as is, several are tried in order by a
+// FailoverSource with the given cross-check tolerance. A positive
+// dumpTimeout replaces DefaultDumpTimeout of the dump sources.
+func NewSources(specs []string, crossCheck float64, dumpTimeout time.Duration) (Fetcher, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no registry sources configured")
+	}
+
+	sources := make([]Fetcher, 0, len(specs))
+	for _, spec := range specs {
+		src, err := NewSource(spec)
+		if err != nil {
+			return nil, err
+		}
+		if dumpTimeout > 0 {
+			switch s := src.(type) {
+			case *DumpXMLSource:
+				s.Timeout = dumpTimeout
+			case *DumpCSVSource:
+				s.Timeout = dumpTimeout
+			}
+		}
+		sources = append(sources, src)
+	}
+
+	if len(sources) == 1 && crossCheck <= 0 {
+		return sources[0], nil
+	}
+
+	f := NewFailoverSource(sources...)
+	f.CrossCheck = crossCheck
+	return f, nil
+}
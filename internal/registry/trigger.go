@@ -0,0 +1,89 @@
+package registry
+
+import (
+	"context"
+	"sync"
+)
+
+// Trigger lets other goroutines (the admin API) run updates through the
+// Start loop, so they are serialized with the scheduled ones and reset
+// its schedule. Pass it in Config.
+type Trigger struct {
+	mu      sync.Mutex
+	refresh *triggerCall // pending, not yet picked up by the loop
+	accept  *triggerCall
+	wake    chan struct{}
+}
+
+type triggerCall struct {
+	done chan struct{}
+	res  UpdateResult
+	err  error
+}
+
+func NewTrigger() *Trigger {
+	return &Trigger{wake: make(chan struct{}, 1)}
+}
+
+// Refresh asks for an immediate update and waits for its outcome.
+// Callers arriving before the loop picks the request up share it; those
+// arriving while it runs get a new one, so every caller sees data fetched
+// after its call.
+func (t *Trigger) Refresh(ctx context.Context) (UpdateResult, error) {
+	return t.wait(ctx, &t.refresh)
+}
+
+// ForceAccept asks to swap in the update last rejected by the Guard,
+// see Guard.ForceAccept, and waits for the outcome.
+func (t *Trigger) ForceAccept(ctx context.Context) (UpdateResult, error) {
+	return t.wait(ctx, &t.accept)
+}
+
+func (t *Trigger) wait(ctx context.Context, slot **triggerCall) (UpdateResult, error) {
+	t.mu.Lock()
+	c := *slot
+	if c == nil {
+		c = &triggerCall{done: make(chan struct{})}
+		*slot = c
+		t.rearm()
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return UpdateResult{}, ctx.Err()
+	case <-c.done:
+		return c.res, c.err
+	}
+}
+
+// requests returns the channel the loop waits on; nil for a nil Trigger,
+// which never fires.
+func (t *Trigger) requests() <-chan struct{} {
+	if t == nil {
+		return nil
+	}
+	return t.wake
+}
+
+// rearm makes requests fire, a pending wakeup is enough.
+func (t *Trigger) rearm() {
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take hands the pending calls over to the loop.
+func (t *Trigger) take() (refresh, accept *triggerCall) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	refresh, accept = t.refresh, t.accept
+	t.refresh, t.accept = nil, nil
+	return refresh, accept
+}
+
+func (c *triggerCall) finish(res UpdateResult, err error) {
+	c.res, c.err = res, err
+	close(c.done)
+}
@@ -2,6 +2,7 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
@@ -15,10 +16,74 @@ type Fetcher interface {
 	FetchRegistry(ctx context.Context) (*domain.Registry, error)
 }
 
+// ErrNotModified is returned by a Fetcher when the source has not changed
+// since its last successful fetch. The updater keeps the current registry
+// and only refreshes its LastUpdated.
+var ErrNotModified = errors.New("registry not modified")
+
+// attemptError is returned by the sources of this package for a fetch
+// that produced no registry, including ErrNotModified. It keeps what the
+// attempt itself read, so the update report does not borrow the Stats
+// of an earlier download.
+type attemptError struct {
+	stats domain.IngestStats
+	err   error
+}
+
+func attemptFailed(st domain.IngestStats, err error) error {
+	return &attemptError{stats: st, err: err}
+}
+
+func (e *attemptError) Error() string { return e.err.Error() }
+func (e *attemptError) Unwrap() error { return e.err }
+
+// attemptStats returns the Stats carried by err, zero if there are none.
+// Of several failed sources the first one tried is reported.
+func attemptStats(err error) domain.IngestStats {
+	var ae *attemptError
+	if errors.As(err, &ae) {
+		return ae.stats
+	}
+	return domain.IngestStats{}
+}
+
+// ValidatorForgetter is implemented by sources that send conditional
+// requests. The updater calls ForgetValidators when the Guard rejects a
+// fetched registry: the source must download it again next time instead
+// of answering ErrNotModified for data that is not being served.
+type ValidatorForgetter interface {
+	ForgetValidators()
+}
+
+// Watcher is implemented by sources that know when their data changed,
+// e.g. a local file. Start reloads on every signal without waiting
+// for the next tick.
+type Watcher interface {
+	Watch(ctx context.Context) <-chan struct{}
+}
+
 type Config struct {
 	Interval       time.Duration // base update interval
 	InitialBackoff time.Duration // initial backoff delay
 	MaxBackoff     time.Duration // maximum backoff delay
+
+	// StartupMaxBackoff caps the backoff until the first successful
+	// update, defaults to 2 minutes.
+	StartupMaxBackoff time.Duration
+
+	// SnapshotPath is where every accepted registry is persisted, see
+	// SaveSnapshot. Empty disables snapshots.
+	SnapshotPath string
+
+	// Guard vets every fetched registry before it is swapped in, nil
+	// accepts everything. Rejections count as failed updates.
+	Guard *Guard
+
+	// Trigger runs on-demand updates through the loop, nil disables them.
+	Trigger *Trigger
+
+	// Monitor records the schedule and every attempt, nil disables it.
+	Monitor *Monitor
 }
 
 // Start runs background registry updates until the context stops.
@@ -32,18 +97,110 @@ func Start(ctx context.Context, cfg Config, src Fetcher, holder *Holder) error {
 	if cfg.MaxBackoff <= 0 {
 		cfg.MaxBackoff = 30 * time.Minute
 	}
+	if cfg.StartupMaxBackoff <= 0 {
+		cfg.StartupMaxBackoff = 2 * time.Minute
+	}
+
+	update := func() (UpdateResult, error) {
+		res, err := updateOnce(ctx, src, holder, cfg.Guard)
+		if cfg.SnapshotPath == "" {
+			return res, err
+		}
+		switch {
+		case err == nil:
+			if err := SaveSnapshot(cfg.SnapshotPath, holder.Get()); err != nil {
+				log.Printf("registry: %v", err)
+			}
+		case errors.Is(err, ErrRejected):
+			// Keep the rejected registry on disk for inspection.
+			if rej, ok := cfg.Guard.Rejected(); ok {
+				if err := SaveSnapshot(cfg.SnapshotPath+".rejected", rej.Registry); err != nil {
+					log.Printf("registry: %v", err)
+				}
+			}
+		}
+		return res, err
+	}
+
+	accept := func() (UpdateResult, error) {
+		reg, err := cfg.Guard.ForceAccept(holder)
+		if err != nil {
+			return UpdateResult{}, err
+		}
+		if cfg.SnapshotPath != "" {
+			if err := SaveSnapshot(cfg.SnapshotPath, reg); err != nil {
+				log.Printf("registry: %v", err)
+			}
+		}
+		return resultOf(reg), nil
+	}
 
-	// Perform the first update immediately on startup
-	if err := updateOnce(ctx, src, holder); err != nil {
-		log.Printf("registry: initial update failed: %v", err)
-	} else {
-		log.Printf("registry: initial update succeeded")
+	// A nil channel never fires, so sources without a watcher only tick.
+	var changes <-chan struct{}
+	if w, ok := src.(Watcher); ok {
+		changes = w.Watch(ctx)
+	}
+
+	// A registry restored from a snapshot younger than the interval is
+	// served as is: after a fleet restart not every pod should hit the
+	// upstream at once. Watched local sources are cheap and may have
+	// changed while we were down, so they are always reloaded.
+	var first time.Duration
+	if last := holder.Get().LastUpdated; !last.IsZero() && changes == nil {
+		first = max(cfg.Interval-time.Since(last), 0)
+	}
+	if first > 0 {
+		log.Printf("registry: registry is fresh, next update in %s", first.Round(time.Second))
 	}
 
-	ticker := time.NewTicker(cfg.Interval)
-	defer ticker.Stop()
+	timer := time.NewTimer(first)
+	defer timer.Stop()
+	cfg.Monitor.scheduled(time.Now().Add(first))
 
-	var consecutiveFailures int
+	var (
+		failures  int
+		succeeded bool
+	)
+	// reschedule records an attempt and arms the timer: the full interval after
+	// a success, the backoff after a failure. Until the first success the
+	// backoff stays short, we may have nothing to serve. Reset also drops
+	// a pending tick, so it is safe while the timer runs.
+	reschedule := func(what string, started time.Time, res UpdateResult, err error) {
+		next := cfg.Interval
+		if err != nil {
+			failures++
+			limit := cfg.MaxBackoff
+			if !succeeded {
+				limit = min(limit, cfg.StartupMaxBackoff)
+			}
+			next = calcBackoff(cfg.InitialBackoff, limit, failures)
+			log.Printf("registry: %s failed (attempt #%d), retry in %s: %v",
+				what, failures, next.Round(time.Millisecond), err)
+		} else {
+			if failures > 0 {
+				log.Printf("registry: update recovered after %d failures", failures)
+			}
+			failures = 0
+			succeeded = true
+			log.Printf("registry: %s succeeded: %s", what, res)
+		}
+		now := time.Now()
+		cfg.Monitor.record(UpdateReport{
+			Trigger:    what,
+			StartedAt:  started,
+			FinishedAt: now,
+			Result:     res,
+			Err:        err,
+		}, failures)
+		cfg.Monitor.scheduled(now.Add(next))
+		timer.Reset(next)
+	}
+
+	if first == 0 {
+		started := time.Now()
+		res, err := update()
+		reschedule("initial update", started, res, err)
+	}
 
 	for {
 		select {
@@ -51,29 +208,35 @@ func Start(ctx context.Context, cfg Config, src Fetcher, holder *Holder) error {
 			log.Printf("registry: updater stopped: %v", ctx.Err())
 			return ctx.Err()
 
-		case <-ticker.C:
-			if err := updateOnce(ctx, src, holder); err != nil {
-				consecutiveFailures++
-				backoff := calcBackoff(cfg.InitialBackoff, cfg.MaxBackoff, consecutiveFailures)
-
-				log.Printf("registry: update failed (attempt #%d), backoff=%s: %v",
-					consecutiveFailures, backoff, err)
-
-				timer := time.NewTimer(backoff)
-				select {
-				case <-ctx.Done():
-					timer.Stop()
-					log.Printf("registry: updater stopped during backoff: %v", ctx.Err())
-					return ctx.Err()
-				case <-timer.C:
+		case <-timer.C:
+			started := time.Now()
+			res, err := update()
+			reschedule("update", started, res, err)
+
+		case <-changes:
+			started := time.Now()
+			res, err := update()
+			reschedule("reload after source change", started, res, err)
+
+		case <-cfg.Trigger.requests():
+			refresh, forced := cfg.Trigger.take()
+			if forced != nil {
+				started := time.Now()
+				res, err := accept()
+				if err != nil {
+					// Nothing was fetched, the schedule stays.
+					log.Printf("registry: force accept failed: %v", err)
+				} else {
+					reschedule("force accept", started, res, nil)
 				}
-				continue
+				forced.finish(res, err)
 			}
-
-			if consecutiveFailures > 0 {
-				log.Printf("registry: update recovered after %d failures", consecutiveFailures)
+			if refresh != nil {
+				started := time.Now()
+				res, err := update()
+				reschedule("requested update", started, res, err)
+				refresh.finish(res, err)
 			}
-			consecutiveFailures = 0
 		}
 	}
 }
@@ -93,16 +256,59 @@ func calcBackoff(initial, max time.Duration, failures int) time.Duration {
 	return backoff + jitter
 }
 
-// updateOnce fetches the registry and updates the holder.
-func updateOnce(ctx context.Context, src Fetcher, holder *Holder) error {
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+// UpdateResult describes a successful update. A failed one only carries
+// the Stats of what the attempt read: the source and the bytes
+// transferred, or the whole ingest of a registry that was rejected.
+type UpdateResult struct {
+	NotModified bool               // source unchanged, current registry kept
+	Version     uint64             // of the registry now in the holder
+	Stats       domain.IngestStats // what this attempt read
+
+	Domains, URLs, HTTPSHosts, IPs int
+}
+
+func (r UpdateResult) String() string {
+	if r.NotModified {
+		return "not modified"
+	}
+	return fmt.Sprintf("version %d: %d domains, %d urls, %d https hosts, %d ips from %s, %d bytes transferred, %d decompressed",
+		r.Version, r.Domains, r.URLs, r.HTTPSHosts, r.IPs, r.Stats.Source, r.Stats.CompressedBytes, r.Stats.DecompressedBytes)
+}
 
+// updateOnce fetches the registry and, if guard accepts it, updates the holder.
+// There is no overall deadline here: every source bounds its own fetch,
+// so a hanging mirror does not eat the time of the ones after it.
+func updateOnce(ctx context.Context, src Fetcher, holder *Holder, guard *Guard) (UpdateResult, error) {
 	reg, err := src.FetchRegistry(ctx)
+	if errors.Is(err, ErrNotModified) {
+		// Same data, just confirmed fresh. The Stats are of this
+		// attempt, not of the download that built the registry.
+		res := resultOf(holder.Touch(time.Now().UTC()))
+		res.NotModified = true
+		res.Stats = attemptStats(err)
+		return res, nil
+	}
 	if err != nil {
-		return err
+		return UpdateResult{Stats: attemptStats(err)}, err
+	}
+	if err := guard.check(holder.Get(), reg); err != nil {
+		if vf, ok := src.(ValidatorForgetter); ok {
+			vf.ForgetValidators()
+		}
+		return UpdateResult{Stats: reg.Stats}, err
 	}
 
 	holder.Set(reg)
-	return nil
+	return resultOf(reg), nil
+}
+
+func resultOf(reg *domain.Registry) UpdateResult {
+	return UpdateResult{
+		Version:    reg.Version,
+		Stats:      reg.Stats,
+		Domains:    len(reg.DomainHashes),
+		URLs:       len(reg.URLHashes),
+		HTTPSHosts: len(reg.URLHostHashes),
+		IPs:        reg.IPs.Len(),
+	}
 }
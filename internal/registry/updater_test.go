@@ -2,7 +2,11 @@ package registry
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"evil-rkn/internal/domain"
 )
@@ -28,12 +32,12 @@ func TestUpdateOnce_Success(t *testing.T) {
 		reg: &domain.Registry{
 			DomainHashes: []uint64{h},
 			URLHashes:    nil,
-			IPs:          make(map[string]struct{}),
+			IPs:          domain.NewIPSet(),
 		},
 	}
 
 	ctx := context.Background()
-	if err := updateOnce(ctx, src, holder); err != nil {
+	if _, err := updateOnce(ctx, src, holder, nil); err != nil {
 		t.Fatalf("updateOnce error: %v", err)
 	}
 
@@ -42,3 +46,214 @@ func TestUpdateOnce_Success(t *testing.T) {
 		t.Fatalf("DomainHashes = %v, want [%d]", got.DomainHashes, h)
 	}
 }
+
+func TestUpdateOnce_NotModified(t *testing.T) {
+	holder := NewHolder()
+	h := domain.HashString64("example.com")
+	old := &domain.Registry{
+		DomainHashes: []uint64{h},
+		LastUpdated:  time.Now().Add(-time.Hour),
+	}
+	holder.Set(old)
+
+	res, err := updateOnce(context.Background(), &fakeSource{err: ErrNotModified}, holder, nil)
+	if err != nil {
+		t.Fatalf("updateOnce error: %v", err)
+	}
+	if !res.NotModified {
+		t.Fatal("expected NotModified result")
+	}
+
+	got := holder.Get()
+	if len(got.DomainHashes) != 1 || got.DomainHashes[0] != h {
+		t.Fatalf("DomainHashes = %v, want [%d]", got.DomainHashes, h)
+	}
+	if !got.LastUpdated.After(old.LastUpdated) {
+		t.Fatalf("LastUpdated = %v, want refreshed", got.LastUpdated)
+	}
+	if !old.LastUpdated.Before(got.LastUpdated) || got == old {
+		t.Fatal("previous registry must not be mutated")
+	}
+}
+
+type watchedSource struct {
+	fetches atomic.Int32
+	changes chan struct{}
+}
+
+func (w *watchedSource) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+	n := w.fetches.Add(1)
+	return &domain.Registry{
+		DomainHashes: []uint64{uint64(n)},
+		LastUpdated:  time.Now(),
+	}, nil
+}
+
+func (w *watchedSource) Watch(ctx context.Context) <-chan struct{} {
+	return w.changes
+}
+
+func TestStart_ReloadsOnSourceChange(t *testing.T) {
+	holder := NewHolder()
+	src := &watchedSource{changes: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Start(ctx, Config{Interval: time.Hour}, src, holder)
+	}()
+
+	src.changes <- struct{}{}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for src.fetches.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("fetches = %d, want reload after change", src.fetches.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
+
+type countingFetcher struct {
+	fetches atomic.Int32
+}
+
+func (c *countingFetcher) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+	c.fetches.Add(1)
+	return &domain.Registry{DomainHashes: []uint64{1}, LastUpdated: time.Now()}, nil
+}
+
+func TestStart_WarmStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.snap")
+
+	// A fresh registry restored from a snapshot is not refetched.
+	holder := NewHolder()
+	holder.Set(&domain.Registry{DomainHashes: []uint64{1}, LastUpdated: time.Now().Add(-time.Minute)})
+	src := &countingFetcher{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_ = Start(ctx, Config{Interval: time.Hour, SnapshotPath: path}, src, holder)
+	if n := src.fetches.Load(); n != 0 {
+		t.Fatalf("fetches = %d, want 0 for a fresh registry", n)
+	}
+
+	// A stale one is, and the result is persisted.
+	holder.Set(&domain.Registry{DomainHashes: []uint64{1}, LastUpdated: time.Now().Add(-2 * time.Hour)})
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_ = Start(ctx, Config{Interval: time.Hour, SnapshotPath: path}, src, holder)
+	if n := src.fetches.Load(); n != 1 {
+		t.Fatalf("fetches = %d, want 1 for a stale registry", n)
+	}
+	if _, err := LoadSnapshot(path); err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+}
+
+func TestStart_Trigger(t *testing.T) {
+	holder := NewHolder()
+	holder.Set(&domain.Registry{DomainHashes: []uint64{1}, LastUpdated: time.Now()})
+	src := &countingFetcher{}
+	trigger := NewTrigger()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Start(ctx, Config{Interval: time.Hour, Trigger: trigger}, src, holder)
+	}()
+
+	res, err := trigger.Refresh(ctx)
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if res.Domains != 1 || res.Version != holder.Get().Version {
+		t.Fatalf("result = %+v, want the new registry", res)
+	}
+	if n := src.fetches.Load(); n != 1 {
+		t.Fatalf("fetches = %d, want 1", n)
+	}
+
+	if _, err := trigger.ForceAccept(ctx); !errors.Is(err, ErrNothingRejected) {
+		t.Fatalf("ForceAccept err = %v, want ErrNothingRejected", err)
+	}
+
+	cancel()
+	<-done
+}
+
+type flakyFetcher struct {
+	fetches  atomic.Int32
+	failures int32
+}
+
+func (f *flakyFetcher) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+	if n := f.fetches.Add(1); n <= f.failures {
+		return nil, errors.New("upstream down")
+	}
+	return &domain.Registry{DomainHashes: []uint64{1}, LastUpdated: time.Now()}, nil
+}
+
+func TestStart_RetriesUntilFirstSuccess(t *testing.T) {
+	holder := NewHolder()
+	src := &flakyFetcher{failures: 2}
+	mon := NewMonitor()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := Config{Interval: time.Hour, InitialBackoff: time.Millisecond, MaxBackoff: time.Hour, Monitor: mon}
+	done := make(chan error, 1)
+	go func() {
+		done <- Start(ctx, cfg, src, holder)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for mon.Status().LastSuccess.IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatalf("no success after %d fetches, status %+v", src.fetches.Load(), mon.Status())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	st := mon.Status()
+	if n := src.fetches.Load(); n != 3 {
+		t.Fatalf("fetches = %d, want 3", n)
+	}
+	if st.Attempts != 0 || st.LastError != nil {
+		t.Fatalf("status = %+v, want failures reset", st)
+	}
+	if until := time.Until(st.NextRun); until < 59*time.Minute {
+		t.Fatalf("next run in %s, want the full interval after a success", until)
+	}
+
+	reports := mon.Reports(0)
+	if len(reports) != 3 {
+		t.Fatalf("got %d reports, want 3", len(reports))
+	}
+	if reports[0].Trigger != "initial update" || reports[0].Err == nil || reports[2].Err != nil {
+		t.Fatalf("reports = %+v, want two failures and a success", reports)
+	}
+	if got := mon.Reports(1); len(got) != 1 || got[0].Result.Version != holder.Get().Version {
+		t.Fatalf("Reports(1) = %+v, want the latest", got)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestCalcBackoff(t *testing.T) {
+	for failures, want := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 10: 10 * time.Second} {
+		got := calcBackoff(time.Second, 10*time.Second, failures)
+		if got < want*8/10 || got > want*12/10 {
+			t.Errorf("calcBackoff(%d) = %s, want %s ±20%%", failures, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+//go:build !linux
+
+package registry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// watchPath polls modification times where inotify is not available.
+func watchPath(ctx context.Context, path string) (<-chan struct{}, error) {
+	last, err := pathStamp(path)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan struct{}, 1)
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(2 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cur, err := pathStamp(path)
+				if err != nil || cur == last {
+					continue
+				}
+				last = cur
+				select {
+				case events <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// pathStamp summarizes sizes and mtimes of path and, for a directory,
+// of the files directly inside it.
+func pathStamp(path string) (int64, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if !st.IsDir() {
+		return st.ModTime().UnixNano() ^ st.Size(), nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return 0, err
+	}
+	stamp := int64(len(entries))
+	for _, e := range entries {
+		fi, err := os.Stat(filepath.Join(path, e.Name()))
+		if err != nil {
+			continue
+		}
+		stamp = stamp*31 + fi.ModTime().UnixNano() ^ fi.Size()
+	}
+	return stamp, nil
+}
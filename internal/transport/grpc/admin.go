@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"evil-rkn/internal/registry"
+	pb "evil-rkn/proto/gen"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AdminServer implements the RegistryAdmin service. Every call needs the
+// bearer token; with an empty token the service refuses everything.
+type AdminServer struct {
+	pb.UnimplementedRegistryAdminServer
+	token   string
+	trigger *registry.Trigger
+}
+
+func NewAdminServer(token string, trigger *registry.Trigger) *AdminServer {
+	return &AdminServer{token: token, trigger: trigger}
+}
+
+func (s *AdminServer) RefreshRegistry(ctx context.Context, req *pb.RefreshRegistryRequest) (*pb.RegistryUpdateResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	res, err := s.trigger.Refresh(ctx)
+	if err != nil {
+		return nil, updateError(err)
+	}
+	return updateToPB(res), nil
+}
+
+func (s *AdminServer) ForceAcceptRegistry(ctx context.Context, req *pb.ForceAcceptRegistryRequest) (*pb.RegistryUpdateResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	res, err := s.trigger.ForceAccept(ctx)
+	if err != nil {
+		return nil, updateError(err)
+	}
+	return updateToPB(res), nil
+}
+
+func (s *AdminServer) authorize(ctx context.Context) error {
+	if s.token == "" {
+		return status.Error(codes.PermissionDenied, "admin API is disabled")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(v, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid or missing admin token")
+}
+
+func updateToPB(res registry.UpdateResult) *pb.RegistryUpdateResponse {
+	return &pb.RegistryUpdateResponse{
+		Version:     res.Version,
+		NotModified: res.NotModified,
+		Source:      res.Stats.Source,
+		Domains:     uint64(res.Domains),
+		Urls:        uint64(res.URLs),
+		HttpsHosts:  uint64(res.HTTPSHosts),
+		Ips:         uint64(res.IPs),
+	}
+}
+
+func updateError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, registry.ErrRejected):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, registry.ErrNothingRejected):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Errorf(codes.Unavailable, "update failed: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"evil-rkn/internal/registry"
+	pb "evil-rkn/proto/gen"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthRecheck is how often freshness is re-evaluated without updates:
+// a registry goes stale just by getting old.
+const healthRecheck = time.Minute
+
+// runHealth drives the status of BlockChecker and of the server as a
+// whole ("") from ready until ctx is done. Watchers are notified on every
+// transition.
+func runHealth(ctx context.Context, hs *health.Server, holder *registry.Holder, ready *registry.ReadyChecker, recheck time.Duration) {
+	changes, unsubscribe := holder.Subscribe()
+	defer unsubscribe()
+
+	ticker := time.NewTicker(recheck)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		err := ready.Check(time.Now()).Err()
+		st := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			st = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		if st != last {
+			if err != nil {
+				log.Printf("grpc health: %s: %v", st, err)
+			} else {
+				log.Printf("grpc health: %s", st)
+			}
+			hs.SetServingStatus("", st)
+			hs.SetServingStatus(pb.BlockChecker_ServiceDesc.ServiceName, st)
+			last = st
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+		case <-ticker.C:
+		}
+	}
+}
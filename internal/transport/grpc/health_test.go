@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"evil-rkn/internal/domain"
+	reginfra "evil-rkn/internal/registry"
+	pb "evil-rkn/proto/gen"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestRunHealth(t *testing.T) {
+	holder := reginfra.NewHolder()
+	hs := health.NewServer()
+	ready, err := reginfra.NewReadyChecker(reginfra.DefaultReadinessPolicy(), holder, nil)
+	if err != nil {
+		t.Fatalf("NewReadyChecker error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runHealth(ctx, hs, holder, ready, 10*time.Millisecond)
+
+	waitStatus := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			resp, err := hs.Check(ctx, &healthpb.HealthCheckRequest{Service: pb.BlockChecker_ServiceDesc.ServiceName})
+			if err == nil && resp.Status == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("status = %v (err %v), want %v", resp.GetStatus(), err, want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// Nothing loaded yet.
+	waitStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	holder.Set(&domain.Registry{
+		DomainHashes: []uint64{domain.HashString64("blocked.com")},
+		IPs:          domain.NewIPSet(),
+		LastUpdated:  time.Now(),
+	})
+	waitStatus(healthpb.HealthCheckResponse_SERVING)
+
+	// Confirmed fresh long ago: stale without a version change.
+	holder.Touch(time.Now().Add(-72 * time.Hour))
+	waitStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+}
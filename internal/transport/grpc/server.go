@@ -2,9 +2,15 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net"
+	"net/netip"
+	"strconv"
 	"strings"
+	"time"
 
 	"evil-rkn/internal/domain"
 	"evil-rkn/internal/registry"
@@ -12,48 +18,454 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type Server struct {
 	pb.UnimplementedBlockCheckerServer
-	holder *registry.Holder
+	holder  *registry.Holder
+	monitor *registry.Monitor
+
+	// quit ends long-lived streams, GracefulStop would wait for them forever.
+	quit chan struct{}
 }
 
-func NewServer(holder *registry.Holder) *Server {
-	return &Server{holder: holder}
+// NewServer serves the registry from holder. The monitor reports the
+// updater's status and may be nil.
+func NewServer(holder *registry.Holder, monitor *registry.Monitor) *Server {
+	return &Server{holder: holder, monitor: monitor, quit: make(chan struct{})}
 }
 
 const maxURLLen = 2048
 
 func (s *Server) Check(ctx context.Context, req *pb.CheckRequest) (*pb.CheckResponse, error) {
-	rawURL := strings.TrimSpace(req.GetUrl())
-	if rawURL == "" {
-		return nil, status.Error(codes.InvalidArgument, "url is required")
+	n, err := normalizeURL(req.GetUrl(), req.GetLenient())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	reg := s.holder.Get()
+	if reg == nil {
+		return nil, status.Error(codes.Unavailable, "registry not initialized")
 	}
-	if len(rawURL) > maxURLLen {
-		return nil, status.Error(codes.InvalidArgument, "url is too long")
+
+	setRegistryHeader(ctx, reg)
+	return checkResponse(domain.IsBlocked(reg, n), req.GetExplain()), nil
+}
+
+func (s *Server) CheckHost(ctx context.Context, req *pb.CheckHostRequest) (*pb.CheckResponse, error) {
+	raw := strings.TrimSpace(req.GetHost())
+	switch {
+	case raw == "":
+		return nil, status.Error(codes.InvalidArgument, "host is required")
+	case len(raw) > maxURLLen:
+		return nil, status.Error(codes.InvalidArgument, "host is too long")
+	case strings.ContainsAny(raw, "/?#"):
+		return nil, status.Error(codes.InvalidArgument, "host must not contain a scheme or path, use Check")
+	}
+	host, err := domain.NormalizeHost(raw)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid host: %v", err)
+	}
+
+	reg := s.holder.Get()
+	if reg == nil {
+		return nil, status.Error(codes.Unavailable, "registry not initialized")
 	}
 
-	n, err := domain.Normalize(rawURL)
+	setRegistryHeader(ctx, reg)
+	return checkResponse(domain.IsHostBlocked(reg, host), req.GetExplain()), nil
+}
+
+func (s *Server) CheckIP(ctx context.Context, req *pb.CheckIPRequest) (*pb.CheckResponse, error) {
+	raw := strings.TrimSpace(req.GetIp())
+	if raw == "" {
+		return nil, status.Error(codes.InvalidArgument, "ip is required")
+	}
+	// Accept IPv6 the way it appears in URLs and logs: "[2001:db8::1]".
+	ip, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(raw, "["), "]"))
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "invalid url: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid ip: %v", err)
+	}
+
+	reg := s.holder.Get()
+	if reg == nil {
+		return nil, status.Error(codes.Unavailable, "registry not initialized")
+	}
+
+	setRegistryHeader(ctx, reg)
+	return checkResponse(domain.IsIPBlocked(reg, ip), req.GetExplain()), nil
+}
+
+// maxBatchSize caps the number of URLs in a CheckBatch request.
+const maxBatchSize = 1000
+
+func (s *Server) CheckBatch(ctx context.Context, req *pb.CheckBatchRequest) (*pb.CheckBatchResponse, error) {
+	urls := req.GetUrls()
+	if len(urls) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "urls are required")
+	}
+	if len(urls) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "too many urls: %d, at most %d", len(urls), maxBatchSize)
 	}
 
+	// One registry for the whole batch, even if an update lands meanwhile.
 	reg := s.holder.Get()
 	if reg == nil {
 		return nil, status.Error(codes.Unavailable, "registry not initialized")
 	}
+	setRegistryHeader(ctx, reg)
+
+	resp := &pb.CheckBatchResponse{
+		Items:               make([]*pb.CheckBatchItem, len(urls)),
+		RegistryVersion:     reg.Version,
+		RegistryFingerprint: reg.Fingerprint,
+	}
+	for i, raw := range urls {
+		item := &pb.CheckBatchItem{Url: raw}
+		if n, err := normalizeURL(raw, false); err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Result = checkResponse(domain.IsBlocked(reg, n), req.GetExplain())
+		}
+		resp.Items[i] = item
+	}
+	return resp, nil
+}
+
+// CheckStream limits. Up to maxStreamPending requests are read ahead,
+// then reading stops and HTTP/2 flow control holds the client back.
+const (
+	maxStreamPending = 64
+	maxStreamChecks  = 1_000_000
+)
+
+func (s *Server) CheckStream(stream pb.BlockChecker_CheckStreamServer) error {
+	ctx := stream.Context()
+
+	reqs := make(chan *pb.CheckStreamRequest, maxStreamPending)
+	recvErr := make(chan error, 1)
+	go func() {
+		defer close(reqs)
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case reqs <- req:
+			case <-ctx.Done():
+				recvErr <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	var (
+		marked bool
+		sent   uint64 // version in the last marker
+		checks int
+	)
+	for {
+		var req *pb.CheckStreamRequest
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.quit:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case r, ok := <-reqs:
+			if !ok {
+				if err := <-recvErr; !errors.Is(err, io.EOF) {
+					return err
+				}
+				return nil
+			}
+			req = r
+		}
+
+		if checks++; checks > maxStreamChecks {
+			return status.Errorf(codes.ResourceExhausted, "more than %d checks in one stream, reconnect", maxStreamChecks)
+		}
+
+		reg := s.holder.Get()
+		if !marked || reg.Version != sent {
+			marker := &pb.RegistryMarker{Version: reg.Version, Fingerprint: reg.Fingerprint}
+			if err := stream.Send(&pb.CheckStreamResponse{Event: &pb.CheckStreamResponse_Registry{Registry: marker}}); err != nil {
+				return err
+			}
+			marked, sent = true, reg.Version
+		}
+
+		res := &pb.CheckStreamResult{Id: req.GetId()}
+		if n, err := normalizeURL(req.GetUrl(), false); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Result = checkResponse(domain.IsBlocked(reg, n), req.GetExplain())
+		}
+		if err := stream.Send(&pb.CheckStreamResponse{Event: &pb.CheckStreamResponse_Result{Result: res}}); err != nil {
+			return err
+		}
+	}
+}
+
+// normalizeURL validates a URL to check, lenient accepts it without a
+// scheme. Errors are meant for the client.
+func normalizeURL(raw string, lenient bool) (domain.NormalizedURL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return domain.NormalizedURL{}, errors.New("url is required")
+	}
+	if len(raw) > maxURLLen {
+		return domain.NormalizedURL{}, errors.New("url is too long")
+	}
 
-	blocked := domain.IsBlocked(reg, n)
+	normalize := domain.Normalize
+	if lenient {
+		normalize = domain.NormalizeLenient
+	}
+	n, err := normalize(raw)
+	if err != nil {
+		return domain.NormalizedURL{}, fmt.Errorf("invalid url: %w", err)
+	}
+	return n, nil
+}
 
-	return &pb.CheckResponse{Blocked: blocked}, nil
+func checkResponse(m domain.Match, explain bool) *pb.CheckResponse {
+	resp := &pb.CheckResponse{Blocked: m.Blocked}
+	if explain {
+		kind := matchKindToPB(m.Kind)
+		resp.MatchKind = &kind
+		resp.MatchedRule = &m.Rule
+		resp.NormalizedUrl = &m.Checked
+	}
+	return resp
+}
+
+// setRegistryHeader tells the client which registry answered, so it can
+// spot replicas serving different data.
+func setRegistryHeader(ctx context.Context, reg *domain.Registry) {
+	// Fails only outside of a real call, e.g. in tests.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(
+		"x-registry-version", strconv.FormatUint(reg.Version, 10),
+		"x-registry-fingerprint", reg.Fingerprint,
+	))
+}
+
+func (s *Server) GetRegistryInfo(ctx context.Context, req *pb.GetRegistryInfoRequest) (*pb.GetRegistryInfoResponse, error) {
+	reg := s.holder.Get()
+
+	resp := &pb.GetRegistryInfoResponse{
+		Version:     reg.Version,
+		Fingerprint: reg.Fingerprint,
+		LastUpdated: timestampOrNil(reg.LastUpdated),
+		Source:      reg.Stats.Source,
+		Domains:     uint64(len(reg.DomainHashes)),
+		Urls:        uint64(len(reg.URLHashes)),
+		HttpsHosts:  uint64(len(reg.URLHostHashes)),
+	}
+	for p := range reg.IPs.All() {
+		if p.IsSingleIP() {
+			resp.Ips++
+		} else {
+			resp.Subnets++
+		}
+	}
+	return resp, nil
+}
+
+// maxDiffs caps GetRegistryDiffs responses.
+const maxDiffs = 64
+
+func (s *Server) GetRegistryDiffs(ctx context.Context, req *pb.GetRegistryDiffsRequest) (*pb.GetRegistryDiffsResponse, error) {
+	limit := int(req.GetLimit())
+	if limit <= 0 || limit > maxDiffs {
+		limit = maxDiffs
+	}
+
+	resp := &pb.GetRegistryDiffsResponse{CurrentVersion: s.holder.Get().Version}
+	for _, d := range s.holder.Diffs(req.GetSinceVersion(), limit) {
+		resp.Diffs = append(resp.Diffs, diffToPB(d))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetRegistryStatus(ctx context.Context, req *pb.GetRegistryStatusRequest) (*pb.GetRegistryStatusResponse, error) {
+	reg := s.holder.Get()
+	st := s.monitor.Status()
+
+	resp := &pb.GetRegistryStatusResponse{
+		CurrentVersion: reg.Version,
+		LastUpdated:    timestampOrNil(reg.LastUpdated),
+		NextUpdate:     timestampOrNil(st.NextRun),
+		FailedAttempts: uint32(st.Attempts),
+		LastSuccess:    timestampOrNil(st.LastSuccess),
+	}
+	if st.LastError != nil {
+		resp.LastError = st.LastError.Error()
+	}
+	for _, rep := range s.monitor.Reports(int(req.GetLimit())) {
+		resp.Reports = append(resp.Reports, reportToPB(rep))
+	}
+	return resp, nil
+}
+
+func (s *Server) WatchRegistry(req *pb.WatchRegistryRequest, stream pb.BlockChecker_WatchRegistryServer) error {
+	// Subscribe first so no version slips between reading and waiting.
+	changes, unsubscribe := s.holder.Subscribe()
+	defer unsubscribe()
+
+	sent := req.GetFromVersion()
+	if sent == 0 {
+		reg := s.holder.Get()
+		if err := stream.Send(&pb.RegistryEvent{Version: reg.Version, LastUpdated: timestampOrNil(reg.LastUpdated)}); err != nil {
+			return err
+		}
+		sent = reg.Version
+	}
+
+	for {
+		var err error
+		if sent, err = s.sendDiffs(stream, sent); err != nil {
+			return err
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.quit:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-changes:
+		}
+	}
+}
+
+// sendDiffs streams the versions after sent and returns the last one sent.
+// If they are no longer in the history, or sent is a version this server
+// never had, a resync event tells the client to reload its state.
+func (s *Server) sendDiffs(stream pb.BlockChecker_WatchRegistryServer, sent uint64) (uint64, error) {
+	reg := s.holder.Get()
+	if reg.Version == sent {
+		return sent, nil
+	}
+
+	diffs := s.holder.Diffs(sent, 0)
+	if sent > reg.Version || len(diffs) == 0 || diffs[0].FromVersion != sent {
+		err := stream.Send(&pb.RegistryEvent{
+			Version:     reg.Version,
+			LastUpdated: timestampOrNil(reg.LastUpdated),
+			Resync:      true,
+		})
+		return reg.Version, err
+	}
+
+	for _, d := range diffs {
+		if err := stream.Send(&pb.RegistryEvent{
+			Version:     d.ToVersion,
+			LastUpdated: timestamppb.New(d.At),
+			Diff:        diffToPB(d),
+		}); err != nil {
+			return sent, err
+		}
+		sent = d.ToVersion
+	}
+	return sent, nil
+}
+
+func diffToPB(d registry.Diff) *pb.RegistryDiff {
+	return &pb.RegistryDiff{
+		FromVersion: d.FromVersion,
+		ToVersion:   d.ToVersion,
+		AppliedAt:   timestamppb.New(d.At),
+		Domains:     kindDiffToPB(d.Domains),
+		Urls:        kindDiffToPB(d.URLs),
+		HttpsHosts:  kindDiffToPB(d.HTTPSHosts),
+		Ips:         kindDiffToPB(d.IPs),
+		Subnets:     kindDiffToPB(d.Subnets),
+	}
+}
+
+func kindDiffToPB(d registry.KindDiff) *pb.EntryDiff {
+	return &pb.EntryDiff{
+		Added:        d.Added,
+		Removed:      d.Removed,
+		AddedCount:   uint64(d.AddedCount),
+		RemovedCount: uint64(d.RemovedCount),
+		Truncated:    d.Truncated(),
+	}
+}
+
+func reportToPB(rep registry.UpdateReport) *pb.UpdateReport {
+	res, st := rep.Result, rep.Result.Stats
+	out := &pb.UpdateReport{
+		Trigger:           rep.Trigger,
+		StartedAt:         timestamppb.New(rep.StartedAt),
+		FinishedAt:        timestamppb.New(rep.FinishedAt),
+		Duration:          durationpb.New(rep.Duration()),
+		NotModified:       res.NotModified,
+		Version:           res.Version,
+		Domains:           uint64(res.Domains),
+		Urls:              uint64(res.URLs),
+		HttpsHosts:        uint64(res.HTTPSHosts),
+		Ips:               uint64(res.IPs),
+		Source:            st.Source,
+		CompressedBytes:   uint64(st.CompressedBytes),
+		DecompressedBytes: uint64(st.DecompressedBytes),
+		SkippedDomains:    skipsToPB(st.Domains),
+		SkippedUrls:       skipsToPB(st.URLs),
+		SkippedIps:        skipsToPB(st.IPs),
+		Malformed:         uint64(st.Malformed),
+		Collisions:        uint64(st.Collisions),
+		SampleDomains:     st.Samples,
+	}
+	if rep.Err != nil {
+		out.Error = rep.Err.Error()
+	}
+	return out
+}
+
+func skipsToPB(s domain.SkipStats) *pb.SkipCounts {
+	return &pb.SkipCounts{
+		Empty:      uint64(s.Empty),
+		Underscore: uint64(s.Underscore),
+		Invalid:    uint64(s.Invalid),
+	}
+}
+
+// timestampOrNil leaves unknown times out of the response.
+func timestampOrNil(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func matchKindToPB(k domain.MatchKind) pb.MatchKind {
+	switch k {
+	case domain.MatchIP:
+		return pb.MatchKind_MATCH_KIND_IP
+	case domain.MatchURL:
+		return pb.MatchKind_MATCH_KIND_URL
+	case domain.MatchHTTPSHost:
+		return pb.MatchKind_MATCH_KIND_HTTPS_HOST
+	case domain.MatchDomain:
+		return pb.MatchKind_MATCH_KIND_DOMAIN
+	case domain.MatchParentDomain:
+		return pb.MatchKind_MATCH_KIND_PARENT_DOMAIN
+	default:
+		return pb.MatchKind_MATCH_KIND_NONE
+	}
 }
 
 // RunGRPCServer starts a gRPC server on the given address and
 // shuts it down gracefully when the context is canceled.
-func RunGRPCServer(ctx context.Context, addr string, holder *registry.Holder) error {
+func RunGRPCServer(ctx context.Context, addr string, holder *registry.Holder, monitor *registry.Monitor, ready *registry.ReadyChecker, admin *AdminServer) error {
 	if addr == "" {
 		// Reasonable default if nothing is provided.
 		addr = ":9090"
@@ -65,12 +477,21 @@ func RunGRPCServer(ctx context.Context, addr string, holder *registry.Holder) er
 	}
 
 	s := grpc.NewServer()
-	pb.RegisterBlockCheckerServer(s, NewServer(holder))
+	srv := NewServer(holder, monitor)
+	pb.RegisterBlockCheckerServer(s, srv)
+	pb.RegisterRegistryAdminServer(s, admin)
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
 	reflection.Register(s)
 
+	go runHealth(ctx, hs, holder, ready, healthRecheck)
+
 	// Stop the server once the context is done (SIGTERM, timeout, etc.).
 	go func() {
 		<-ctx.Done()
+		// Tell watching probes we are going away before streams end.
+		hs.Shutdown()
+		close(srv.quit)
 		s.GracefulStop()
 	}()
 
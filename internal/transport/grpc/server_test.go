@@ -2,7 +2,9 @@ package grpc
 
 import (
 	"context"
+	"io"
 	"net"
+	"net/netip"
 	"testing"
 	"time"
 
@@ -11,6 +13,9 @@ import (
 	pb "evil-rkn/proto/gen"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func newTestGRPCHolder() *reginfra.Holder {
@@ -18,7 +23,7 @@ func newTestGRPCHolder() *reginfra.Holder {
 	reg := &domain.Registry{
 		DomainHashes: []uint64{domain.HashString64("blocked.com")},
 		URLHashes:    nil,
-		IPs:          make(map[string]struct{}),
+		IPs:          domain.NewIPSet(),
 	}
 	h.Set(reg)
 	return h
@@ -33,7 +38,7 @@ func startTestGRPCServer(t *testing.T, holder *reginfra.Holder) (addr string, st
 	}
 
 	s := grpc.NewServer()
-	pb.RegisterBlockCheckerServer(s, NewServer(holder))
+	pb.RegisterBlockCheckerServer(s, NewServer(holder, nil))
 
 	go func() {
 		_ = s.Serve(lis)
@@ -58,7 +63,8 @@ func TestGRPCCheck_Blocked(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 
-	resp, err := client.Check(ctx, &pb.CheckRequest{Url: "https://blocked.com"})
+	var header metadata.MD
+	resp, err := client.Check(ctx, &pb.CheckRequest{Url: "https://blocked.com"}, grpc.Header(&header))
 	if err != nil {
 		t.Fatalf("Check error: %v", err)
 	}
@@ -66,4 +72,409 @@ func TestGRPCCheck_Blocked(t *testing.T) {
 	if !resp.Blocked {
 		t.Fatalf("expected blocked=true, got false")
 	}
+	reg := holder.Get()
+	if got := header.Get("x-registry-fingerprint"); len(got) != 1 || got[0] != reg.Fingerprint {
+		t.Fatalf("fingerprint header = %v, want %q", got, reg.Fingerprint)
+	}
+	if got := header.Get("x-registry-version"); len(got) != 1 || got[0] != "1" {
+		t.Fatalf("version header = %v, want 1", got)
+	}
+}
+
+func TestGRPCCheckBatch(t *testing.T) {
+	srv := NewServer(newTestGRPCHolder(), nil)
+
+	resp, err := srv.CheckBatch(context.Background(), &pb.CheckBatchRequest{
+		Urls:    []string{"https://sub.blocked.com/x", "https://example.com", "", "not a url"},
+		Explain: true,
+	})
+	if err != nil {
+		t.Fatalf("CheckBatch error: %v", err)
+	}
+	if len(resp.Items) != 4 || resp.RegistryVersion != 1 || resp.RegistryFingerprint == "" {
+		t.Fatalf("got %d items for version %d, want 4 for version 1", len(resp.Items), resp.RegistryVersion)
+	}
+
+	if r := resp.Items[0].GetResult(); !r.GetBlocked() || r.GetMatchKind() != pb.MatchKind_MATCH_KIND_PARENT_DOMAIN {
+		t.Errorf("item 0 = %v, want blocked by parent domain", resp.Items[0])
+	}
+	if r := resp.Items[1].GetResult(); r == nil || r.GetBlocked() {
+		t.Errorf("item 1 = %v, want not blocked", resp.Items[1])
+	}
+	for _, item := range resp.Items[2:] {
+		if item.Error == "" || item.Result != nil {
+			t.Errorf("item %q = %v, want an error without result", item.Url, item)
+		}
+	}
+
+	_, err = srv.CheckBatch(context.Background(), &pb.CheckBatchRequest{Urls: make([]string, maxBatchSize+1)})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("oversized batch: err = %v, want InvalidArgument", err)
+	}
+}
+
+func TestGRPCGetRegistryInfo(t *testing.T) {
+	holder := reginfra.NewHolder()
+	ips := domain.NewIPSet()
+	ips.Insert(netip.MustParsePrefix("203.0.113.5/32"))
+	ips.Insert(netip.MustParsePrefix("198.51.100.0/24"))
+	holder.Set(&domain.Registry{
+		DomainHashes: []uint64{domain.HashString64("blocked.com")},
+		IPs:          ips,
+		LastUpdated:  time.Now(),
+		Stats:        domain.IngestStats{Source: "test"},
+	})
+
+	resp, err := NewServer(holder, nil).GetRegistryInfo(context.Background(), &pb.GetRegistryInfoRequest{})
+	if err != nil {
+		t.Fatalf("GetRegistryInfo error: %v", err)
+	}
+	if resp.Version != 1 || resp.Fingerprint == "" || resp.Fingerprint != holder.Get().Fingerprint {
+		t.Fatalf("version %d fingerprint %q, want version 1 with the holder's fingerprint", resp.Version, resp.Fingerprint)
+	}
+	if resp.Domains != 1 || resp.Ips != 1 || resp.Subnets != 1 || resp.Source != "test" || resp.LastUpdated == nil {
+		t.Fatalf("info = %v", resp)
+	}
+}
+
+func TestGRPCCheck_Explain(t *testing.T) {
+	holder := newTestGRPCHolder()
+	addr, stop := startTestGRPCServer(t, holder)
+	defer stop()
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewBlockCheckerClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	resp, err := client.Check(ctx, &pb.CheckRequest{Url: "https://Sub.Blocked.com/a/../b", Explain: true})
+	if err != nil {
+		t.Fatalf("Check error: %v", err)
+	}
+
+	if !resp.Blocked || resp.GetMatchKind() != pb.MatchKind_MATCH_KIND_PARENT_DOMAIN {
+		t.Fatalf("got blocked=%v kind=%v, want parent domain match", resp.Blocked, resp.GetMatchKind())
+	}
+	if resp.GetMatchedRule() != "blocked.com" {
+		t.Errorf("matched_rule = %q, want %q", resp.GetMatchedRule(), "blocked.com")
+	}
+	if resp.GetNormalizedUrl() != "https://sub.blocked.com/b" {
+		t.Errorf("normalized_url = %q, want %q", resp.GetNormalizedUrl(), "https://sub.blocked.com/b")
+	}
+
+	resp, err = client.Check(ctx, &pb.CheckRequest{Url: "https://blocked.com"})
+	if err != nil {
+		t.Fatalf("Check error: %v", err)
+	}
+	if resp.MatchKind != nil || resp.MatchedRule != nil || resp.NormalizedUrl != nil {
+		t.Fatalf("details must be empty without explain, got %v", resp)
+	}
+}
+
+func TestGRPCGetRegistryDiffs(t *testing.T) {
+	holder := newTestGRPCHolder()
+	holder.Set(&domain.Registry{
+		DomainHashes: []uint64{domain.HashString64("new.com")},
+		DomainKeys:   domain.NewKeyTable([]string{"new.com"}),
+		IPs:          domain.NewIPSet(),
+	})
+	addr, stop := startTestGRPCServer(t, holder)
+	defer stop()
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewBlockCheckerClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	resp, err := client.GetRegistryDiffs(ctx, &pb.GetRegistryDiffsRequest{SinceVersion: 1})
+	if err != nil {
+		t.Fatalf("GetRegistryDiffs error: %v", err)
+	}
+
+	if resp.CurrentVersion != 2 || len(resp.Diffs) != 1 {
+		t.Fatalf("got version %d with %d diffs, want version 2 with 1 diff", resp.CurrentVersion, len(resp.Diffs))
+	}
+	d := resp.Diffs[0]
+	if d.FromVersion != 1 || d.ToVersion != 2 || d.AppliedAt == nil {
+		t.Fatalf("diff %d->%d at %v, want 1->2 with time", d.FromVersion, d.ToVersion, d.AppliedAt)
+	}
+	// The first registry has no keys: only the counts are known.
+	if got := d.GetDomains(); got.AddedCount != 1 || got.RemovedCount != 1 || len(got.Added) != 0 {
+		t.Fatalf("domains diff = %v, want +1 -1 without entries", got)
+	}
+}
+
+func TestGRPCWatchRegistry(t *testing.T) {
+	holder := newTestGRPCHolder()
+	addr, stop := startTestGRPCServer(t, holder)
+	defer stop()
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewBlockCheckerClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchRegistry(ctx, &pb.WatchRegistryRequest{})
+	if err != nil {
+		t.Fatalf("WatchRegistry error: %v", err)
+	}
+
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv error: %v", err)
+	}
+	if ev.Version != 1 || ev.Diff != nil || ev.Resync {
+		t.Fatalf("first event = %v, want current version 1", ev)
+	}
+
+	holder.Set(&domain.Registry{IPs: domain.NewIPSet()})
+	holder.Set(&domain.Registry{DomainHashes: []uint64{1, 2}, IPs: domain.NewIPSet()})
+
+	for want := uint64(2); want <= 3; want++ {
+		ev, err = stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv error: %v", err)
+		}
+		if ev.Version != want || ev.Diff.GetToVersion() != want || ev.Diff.GetFromVersion() != want-1 {
+			t.Fatalf("event = %v, want diff to version %d", ev, want)
+		}
+	}
+	if got := ev.Diff.GetDomains().GetAddedCount(); got != 2 {
+		t.Fatalf("added domains = %d, want 2", got)
+	}
+}
+
+func TestGRPCWatchRegistry_NotLoaded(t *testing.T) {
+	addr, stop := startTestGRPCServer(t, reginfra.NewHolder())
+	defer stop()
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := pb.NewBlockCheckerClient(conn).WatchRegistry(ctx, &pb.WatchRegistryRequest{})
+	if err != nil {
+		t.Fatalf("WatchRegistry error: %v", err)
+	}
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv error: %v", err)
+	}
+	if ev.Version != 0 || ev.LastUpdated != nil {
+		t.Fatalf("first event = %v, want version 0 without last_updated", ev)
+	}
+}
+
+func TestGRPCWatchRegistry_Resume(t *testing.T) {
+	holder := newTestGRPCHolder()
+	holder.Set(&domain.Registry{IPs: domain.NewIPSet()})
+	holder.Set(&domain.Registry{DomainHashes: []uint64{1}, IPs: domain.NewIPSet()})
+	addr, stop := startTestGRPCServer(t, holder)
+	defer stop()
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewBlockCheckerClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Versions 2 and 3 are replayed from the history.
+	stream, err := client.WatchRegistry(ctx, &pb.WatchRegistryRequest{FromVersion: 1})
+	if err != nil {
+		t.Fatalf("WatchRegistry error: %v", err)
+	}
+	for want := uint64(2); want <= 3; want++ {
+		ev, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv error: %v", err)
+		}
+		if ev.Version != want || ev.Diff == nil {
+			t.Fatalf("event = %v, want replayed diff to version %d", ev, want)
+		}
+	}
+
+	// A version this server never had cannot be resumed.
+	stream, err = client.WatchRegistry(ctx, &pb.WatchRegistryRequest{FromVersion: 100})
+	if err != nil {
+		t.Fatalf("WatchRegistry error: %v", err)
+	}
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv error: %v", err)
+	}
+	if !ev.Resync || ev.Version != 3 || ev.Diff != nil {
+		t.Fatalf("event = %v, want resync at version 3", ev)
+	}
+}
+
+func TestAdminServer_Authorize(t *testing.T) {
+	srv := NewAdminServer("secret", reginfra.NewTrigger())
+	withToken := func(v string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", v))
+	}
+
+	cases := []struct {
+		name string
+		srv  *AdminServer
+		ctx  context.Context
+		want codes.Code
+	}{
+		{"no token", srv, context.Background(), codes.Unauthenticated},
+		{"wrong token", srv, withToken("Bearer nope"), codes.Unauthenticated},
+		{"disabled", NewAdminServer("", nil), withToken("Bearer "), codes.PermissionDenied},
+	}
+	for _, tc := range cases {
+		_, err := tc.srv.RefreshRegistry(tc.ctx, &pb.RefreshRegistryRequest{})
+		if got := status.Code(err); got != tc.want {
+			t.Errorf("%s: code = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+
+	// Authorized, but no updater loop picks the request up.
+	ctx, cancel := context.WithTimeout(withToken("Bearer secret"), 20*time.Millisecond)
+	defer cancel()
+	_, err := srv.RefreshRegistry(ctx, &pb.RefreshRegistryRequest{})
+	if got := status.Code(err); got != codes.DeadlineExceeded {
+		t.Fatalf("authorized: code = %s, want %s", got, codes.DeadlineExceeded)
+	}
+}
+
+type stubFetcher struct{ reg *domain.Registry }
+
+func (f stubFetcher) FetchRegistry(ctx context.Context) (*domain.Registry, error) {
+	return f.reg, nil
+}
+
+func TestGRPCGetRegistryStatus(t *testing.T) {
+	holder := reginfra.NewHolder()
+	monitor := reginfra.NewMonitor()
+	src := stubFetcher{reg: &domain.Registry{
+		DomainHashes: []uint64{domain.HashString64("blocked.com")},
+		IPs:          domain.NewIPSet(),
+		LastUpdated:  time.Now(),
+		Stats: domain.IngestStats{
+			Source:  "test",
+			Domains: domain.SkipStats{Underscore: 2},
+			Samples: []string{"blocked.com"},
+		},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reginfra.Start(ctx, reginfra.Config{Interval: time.Hour, Monitor: monitor}, src, holder)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for monitor.Status().LastSuccess.IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("updater did not run")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	resp, err := NewServer(holder, monitor).GetRegistryStatus(ctx, &pb.GetRegistryStatusRequest{})
+	if err != nil {
+		t.Fatalf("GetRegistryStatus error: %v", err)
+	}
+	if resp.CurrentVersion != 1 || resp.NextUpdate == nil || resp.LastError != "" || resp.FailedAttempts != 0 {
+		t.Fatalf("status = %v, want version 1 scheduled without errors", resp)
+	}
+	if len(resp.Reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(resp.Reports))
+	}
+	rep := resp.Reports[0]
+	if rep.Trigger != "initial update" || rep.Source != "test" || rep.Domains != 1 ||
+		rep.GetSkippedDomains().GetUnderscore() != 2 || len(rep.SampleDomains) != 1 || rep.Duration == nil {
+		t.Fatalf("report = %v", rep)
+	}
+}
+
+func TestGRPCCheckStream(t *testing.T) {
+	holder := newTestGRPCHolder()
+	addr, stop := startTestGRPCServer(t, holder)
+	defer stop()
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := pb.NewBlockCheckerClient(conn).CheckStream(ctx)
+	if err != nil {
+		t.Fatalf("CheckStream error: %v", err)
+	}
+	recv := func() *pb.CheckStreamResponse {
+		t.Helper()
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv error: %v", err)
+		}
+		return resp
+	}
+	send := func(id, url string) {
+		t.Helper()
+		if err := stream.Send(&pb.CheckStreamRequest{Id: id, Url: url}); err != nil {
+			t.Fatalf("Send error: %v", err)
+		}
+	}
+
+	send("a", "https://blocked.com")
+	send("b", "bad url")
+	if m := recv().GetRegistry(); m.GetVersion() != 1 || m.GetFingerprint() == "" {
+		t.Fatalf("first event = %v, want marker for version 1", m)
+	}
+	if r := recv().GetResult(); r.GetId() != "a" || !r.GetResult().GetBlocked() {
+		t.Fatalf("result = %v, want a blocked", r)
+	}
+	if r := recv().GetResult(); r.GetId() != "b" || r.GetError() == "" {
+		t.Fatalf("result = %v, want b failed", r)
+	}
+
+	// A new registry is announced before the next result.
+	holder.Set(&domain.Registry{IPs: domain.NewIPSet()})
+	send("c", "https://blocked.com")
+	if m := recv().GetRegistry(); m.GetVersion() != 2 {
+		t.Fatalf("event = %v, want marker for version 2", m)
+	}
+	if r := recv().GetResult(); r.GetId() != "c" || r.GetResult().GetBlocked() {
+		t.Fatalf("result = %v, want c not blocked", r)
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend error: %v", err)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("Recv after CloseSend = %v, want EOF", err)
+	}
 }
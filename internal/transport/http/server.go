@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"evil-rkn/internal/registry"
 	"log"
 	"net/http"
@@ -14,7 +15,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-func RunHTTPGatewayServer(ctx context.Context, httpAddr, grpcEndpoint string, holder *registry.Holder) error {
+func RunHTTPGatewayServer(ctx context.Context, httpAddr, grpcEndpoint string, ready *registry.ReadyChecker) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -24,36 +25,34 @@ func RunHTTPGatewayServer(ctx context.Context, httpAddr, grpcEndpoint string, ho
 	if err := pb.RegisterBlockCheckerHandlerFromEndpoint(ctx, gwMux, grpcEndpoint, opts); err != nil {
 		return err
 	}
+	if err := pb.RegisterRegistryAdminHandlerFromEndpoint(ctx, gwMux, grpcEndpoint, opts); err != nil {
+		return err
+	}
 
 	// Main HTTP mux, routing all requests through the gRPC-Gateway
 	mux := http.NewServeMux()
 	mux.Handle("/", gwMux)
 
+	// The watch stream lives as long as the client wants, lift WriteTimeout for it.
+	mux.HandleFunc("/api/v1/registry/watch", func(w http.ResponseWriter, r *http.Request) {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		gwMux.ServeHTTP(w, r)
+	})
+
+	// A refresh waits for the whole download, lift WriteTimeout for it too.
+	mux.HandleFunc("/api/v1/admin/registry:refresh", func(w http.ResponseWriter, r *http.Request) {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		gwMux.ServeHTTP(w, r)
+	})
+
 	// /healthz — basic liveness check
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
 
-	// /readyz — readiness check; in production it can be replaced with real gRPC health probing
-	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
-		reg := holder.Get()
-		if reg == nil || reg.LastUpdated.IsZero() || len(reg.DomainHashes) == 0 {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			_, _ = w.Write([]byte("stale"))
-			return
-		}
-
-		age := time.Since(reg.LastUpdated)
-		if age < 0 || age > 48*time.Hour {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			_, _ = w.Write([]byte("stale"))
-			return
-		}
-
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ready"))
-	})
+	// /readyz — readiness check, the same rules drive gRPC health checks
+	mux.Handle("/readyz", readyzHandler(ready))
 
 	srv := &http.Server{
 		Addr:         httpAddr,
@@ -76,3 +75,19 @@ func RunHTTPGatewayServer(ctx context.Context, httpAddr, grpcEndpoint string, ho
 	log.Printf("HTTP gateway listening on %s, proxying to gRPC %s", httpAddr, grpcEndpoint)
 	return srv.ListenAndServe()
 }
+
+// readyzHandler answers 200 while ready accepts the served registry and
+// 503 otherwise, with every criterion as JSON.
+func readyzHandler(ready *registry.ReadyChecker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := ready.Check(time.Now())
+		code := http.StatusOK
+		if !res.Ready {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(res)
+	})
+}
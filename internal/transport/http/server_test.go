@@ -2,8 +2,10 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,7 +22,7 @@ func newTestHolder() *registry.Holder {
 	reg := &domain.Registry{
 		DomainHashes: []uint64{domain.HashString64("blocked.com")},
 		URLHashes:    nil,
-		IPs:          make(map[string]struct{}),
+		IPs:          domain.NewIPSet(),
 	}
 	h.Set(reg)
 	return h
@@ -29,7 +31,7 @@ func newTestHolder() *registry.Holder {
 func newTestGatewayMux(tb testing.TB, holder *registry.Holder) http.Handler {
 	tb.Helper()
 
-	srv := grpcTransport.NewServer(holder)
+	srv := grpcTransport.NewServer(holder, nil)
 
 	mux := runtime.NewServeMux()
 	if err := pb.RegisterBlockCheckerHandlerServer(context.Background(), mux, srv); err != nil {
@@ -90,28 +92,117 @@ func TestHTTPGateway_InvalidURL(t *testing.T) {
 		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
 	}
 }
-func newReadyzMux(h *registry.Holder) http.Handler {
-	mux := http.NewServeMux()
+func TestHTTPGateway_Explain(t *testing.T) {
+	holder := newTestHolder()
+	h := newTestGatewayMux(t, holder)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/check?url=https://blocked.com/x&explain=true", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
 
-	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
-		reg := h.Get()
-		if reg == nil || reg.LastUpdated.IsZero() || len(reg.DomainHashes) == 0 {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			_, _ = w.Write([]byte("stale"))
-			return
+	body := w.Body.String()
+	for _, want := range []string{`"blocked":true`, `"matchKind":"MATCH_KIND_DOMAIN"`, `"matchedRule":"blocked.com"`, `"normalizedUrl":"https://blocked.com/x"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body = %q, want it to contain %s", body, want)
 		}
+	}
+}
+
+func TestHTTPGateway_CheckHostAndIP(t *testing.T) {
+	holder := newTestHolder()
+	h := newTestGatewayMux(t, holder)
 
-		age := time.Since(reg.LastUpdated)
-		if age < 0 || age > 48*time.Hour {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			_, _ = w.Write([]byte("stale"))
-			return
+	for target, want := range map[string]string{
+		"/api/v1/check/host?host=Sub.Blocked.com:443&explain=true": `"matchKind":"MATCH_KIND_PARENT_DOMAIN"`,
+		"/api/v1/check/host?host=example.com":                      `{"blocked":false}`,
+		"/api/v1/check/ip?ip=203.0.113.5":                          `{"blocked":false}`,
+		"/api/v1/check?url=blocked.com/x&lenient=true":             `{"blocked":true}`,
+	} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want %d", target, w.Code, http.StatusOK)
 		}
+		if body := w.Body.String(); !strings.Contains(body, want) {
+			t.Errorf("%s: body = %q, want it to contain %s", target, body, want)
+		}
+	}
+
+	for _, target := range []string{"/api/v1/check/host?host=https://blocked.com", "/api/v1/check/ip?ip=blocked.com"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
 
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ready"))
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", target, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestHTTPGateway_CheckBatch(t *testing.T) {
+	holder := newTestHolder()
+	h := newTestGatewayMux(t, holder)
+
+	body := `{"urls":["https://blocked.com/a","https://example.com","example.com"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/check:batch", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	got := w.Body.String()
+	for _, want := range []string{`"result":{"blocked":true}`, `"result":{"blocked":false}`, `"error":"invalid url:`, `"registryVersion":"1"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("body = %q, want it to contain %s", got, want)
+		}
+	}
+}
+
+func TestHTTPGateway_RegistryDiffs(t *testing.T) {
+	holder := registry.NewHolder()
+	holder.Set(&domain.Registry{
+		DomainHashes: []uint64{domain.HashString64("blocked.com")},
+		DomainKeys:   domain.NewKeyTable([]string{"blocked.com"}),
+		IPs:          domain.NewIPSet(),
 	})
+	h := newTestGatewayMux(t, holder)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/registry/diffs?since_version=0&limit=5", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
 
+	body := w.Body.String()
+	for _, want := range []string{`"currentVersion":"1"`, `"toVersion":"1"`, `"added":["blocked.com"]`, `"addedCount":"1"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body = %q, want it to contain %s", body, want)
+		}
+	}
+}
+
+func newReadyzMux(h *registry.Holder) http.Handler {
+	ready, err := registry.NewReadyChecker(registry.DefaultReadinessPolicy(), h, nil)
+	if err != nil {
+		panic(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/readyz", readyzHandler(ready))
 	return mux
 }
 
@@ -134,7 +225,7 @@ func TestReadyz_NotReady_WhenRegistryTooOld(t *testing.T) {
 
 	reg := &domain.Registry{
 		DomainHashes: []uint64{domain.HashString64("blocked.com")},
-		IPs:          make(map[string]struct{}),
+		IPs:          domain.NewIPSet(),
 		LastUpdated:  time.Now().Add(-72 * time.Hour), // 3 дня назад
 	}
 	h.Set(reg)
@@ -149,6 +240,16 @@ func TestReadyz_NotReady_WhenRegistryTooOld(t *testing.T) {
 	if w.Code != http.StatusServiceUnavailable {
 		t.Fatalf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
 	}
+
+	var res registry.Readiness
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("body %q is not JSON: %v", w.Body.String(), err)
+	}
+	for _, c := range res.Criteria {
+		if c.OK != (c.Name != "age") {
+			t.Errorf("criterion %+v, want only age to fail", c)
+		}
+	}
 }
 
 func TestReadyz_Ready_WhenFresh(t *testing.T) {
@@ -156,7 +257,7 @@ func TestReadyz_Ready_WhenFresh(t *testing.T) {
 
 	reg := &domain.Registry{
 		DomainHashes: []uint64{domain.HashString64("blocked.com")},
-		IPs:          make(map[string]struct{}),
+		IPs:          domain.NewIPSet(),
 		LastUpdated:  time.Now().Add(-time.Hour), // 1 час назад
 	}
 	h.Set(reg)
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"evil-rkn/internal/registry"
+)
+
+// SignatureHeader carries "sha256=" + hex HMAC-SHA256 of the request body
+// keyed with the shared secret, see Sign.
+const SignatureHeader = "X-Webhook-Signature"
+
+// DeliveryHeader carries an ID unique per payload. Deliveries are retried,
+// so receivers should use it to drop duplicates.
+const DeliveryHeader = "X-Webhook-Delivery"
+
+// Payload is the JSON body posted to webhook targets after an accepted
+// registry update.
+type Payload struct {
+	Version     uint64    `json:"version"`
+	FromVersion uint64    `json:"from_version"`
+	AppliedAt   time.Time `json:"applied_at"`
+
+	// Summary is set when the change is too large to list: the entry
+	// lists are omitted and only the counts are sent.
+	Summary bool `json:"summary"`
+
+	Domains    Changes `json:"domains"`
+	URLs       Changes `json:"urls"`
+	HTTPSHosts Changes `json:"https_hosts"`
+	IPs        Changes `json:"ips"`
+	Subnets    Changes `json:"subnets"`
+}
+
+// Changes describes the entries of one kind added and removed.
+type Changes struct {
+	AddedCount   int      `json:"added_count"`
+	RemovedCount int      `json:"removed_count"`
+	Added        []string `json:"added,omitempty"`
+	Removed      []string `json:"removed,omitempty"`
+}
+
+// newPayload converts a registry diff. The entries are listed only when
+// all of them are known and there are at most maxEntries in total.
+func newPayload(d registry.Diff, maxEntries int) Payload {
+	kinds := []registry.KindDiff{d.Domains, d.URLs, d.HTTPSHosts, d.IPs, d.Subnets}
+
+	var total int
+	summary := false
+	for _, k := range kinds {
+		total += k.AddedCount + k.RemovedCount
+		// Without keys the counts are known but the entries are not.
+		if k.Truncated() {
+			summary = true
+		}
+	}
+	if total > maxEntries {
+		summary = true
+	}
+
+	changes := func(k registry.KindDiff) Changes {
+		c := Changes{AddedCount: k.AddedCount, RemovedCount: k.RemovedCount}
+		if !summary {
+			c.Added, c.Removed = k.Added, k.Removed
+		}
+		return c
+	}
+
+	return Payload{
+		Version:     d.ToVersion,
+		FromVersion: d.FromVersion,
+		AppliedAt:   d.At,
+		Summary:     summary,
+		Domains:     changes(d.Domains),
+		URLs:        changes(d.URLs),
+		HTTPSHosts:  changes(d.HTTPSHosts),
+		IPs:         changes(d.IPs),
+		Subnets:     changes(d.Subnets),
+	}
+}
+
+// Sign returns the SignatureHeader value for body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
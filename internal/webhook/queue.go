@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// delivery is one payload pending for one target.
+type delivery struct {
+	Seq         uint64          `json:"seq"`
+	ID          string          `json:"id"`
+	Target      string          `json:"target"`
+	Body        json.RawMessage `json:"body"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+}
+
+// queue keeps pending deliveries in order. With a directory every
+// delivery is also a file there, written atomically, so a restart
+// resumes where the previous process stopped. Without one the queue
+// lives in memory only.
+type queue struct {
+	dir     string
+	items   []*delivery // by Seq
+	nextSeq uint64
+}
+
+func openQueue(dir string) (*queue, error) {
+	q := &queue{dir: dir, nextSeq: 1}
+	if dir == "" {
+		return q, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("webhook queue: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("webhook queue: %w", err)
+	}
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("webhook queue: %w", err)
+		}
+		var d delivery
+		if err := json.Unmarshal(data, &d); err != nil {
+			// A torn file cannot come from an atomic rename; don't let
+			// somebody else's junk stop the service.
+			continue
+		}
+		q.items = append(q.items, &d)
+		q.nextSeq = max(q.nextSeq, d.Seq+1)
+	}
+	sort.Slice(q.items, func(i, j int) bool { return q.items[i].Seq < q.items[j].Seq })
+	return q, nil
+}
+
+// push adds a delivery and persists it.
+func (q *queue) push(d *delivery) error {
+	d.Seq = q.nextSeq
+	q.nextSeq++
+	if err := q.save(d); err != nil {
+		return err
+	}
+	q.items = append(q.items, d)
+	return nil
+}
+
+// save persists d after it changed.
+func (q *queue) save(d *delivery) error {
+	if q.dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(q.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("webhook queue: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("webhook queue: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("webhook queue: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("webhook queue: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), q.path(d)); err != nil {
+		return fmt.Errorf("webhook queue: %w", err)
+	}
+	return nil
+}
+
+// remove drops a delivered or abandoned delivery.
+func (q *queue) remove(d *delivery) error {
+	for i, it := range q.items {
+		if it == d {
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			break
+		}
+	}
+	if q.dir == "" {
+		return nil
+	}
+	if err := os.Remove(q.path(d)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("webhook queue: %w", err)
+	}
+	return nil
+}
+
+func (q *queue) path(d *delivery) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d.json", d.Seq))
+}
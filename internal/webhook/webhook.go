@@ -0,0 +1,234 @@
+// Package webhook posts registry changes to HTTP callbacks.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"evil-rkn/internal/registry"
+)
+
+type Config struct {
+	Targets []string // URLs to POST payloads to
+	Secret  string   // HMAC key for SignatureHeader, empty leaves requests unsigned
+
+	// QueueDir persists pending deliveries across restarts, empty keeps
+	// them in memory only.
+	QueueDir string
+
+	// MaxEntries is the largest number of entries listed in a payload,
+	// bigger changes are sent as a summary with counts only.
+	MaxEntries int
+
+	// MaxAttempts is how many times a delivery is tried before it is
+	// dropped. Retries back off from InitialBackoff up to MaxBackoff.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Notifier queues a payload per target for every registry version change
+// and delivers them in order, retrying failed ones.
+type Notifier struct {
+	cfg   Config
+	http  *http.Client
+	queue *queue
+
+	holder      *registry.Holder
+	changes     <-chan struct{}
+	unsubscribe func()
+	last        uint64 // newest version queued
+}
+
+// NewNotifier starts watching holder. Versions published before, including
+// a registry restored from a snapshot, are not notified; later ones are
+// queued once Run is called.
+func NewNotifier(cfg Config, holder *registry.Holder) (*Notifier, error) {
+	if len(cfg.Targets) == 0 {
+		return nil, errors.New("webhook: no targets configured")
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 1000
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 20
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 10 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 30 * time.Minute
+	}
+
+	q, err := openQueue(cfg.QueueDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(q.items) > 0 {
+		log.Printf("webhook: %d deliveries pending from the previous run", len(q.items))
+	}
+
+	n := &Notifier{
+		cfg:    cfg,
+		http:   &http.Client{Timeout: 10 * time.Second},
+		queue:  q,
+		holder: holder,
+	}
+	n.changes, n.unsubscribe = holder.Subscribe()
+	n.last = holder.Get().Version
+	return n, nil
+}
+
+// Run delivers notifications until ctx is done.
+func (n *Notifier) Run(ctx context.Context) error {
+	defer n.unsubscribe()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-n.changes:
+			n.enqueue()
+		case <-timer.C:
+		}
+
+		timer.Stop()
+		if wait, ok := n.deliverDue(ctx); ok {
+			timer.Reset(wait)
+		}
+	}
+}
+
+// enqueue queues payloads for the versions published since the last call.
+func (n *Notifier) enqueue() {
+	diffs := n.holder.Diffs(n.last, 0)
+	if len(diffs) > 0 && diffs[0].FromVersion != n.last {
+		log.Printf("webhook: versions %d..%d fell out of the history, not notified", n.last+1, diffs[0].FromVersion)
+	}
+
+	for _, d := range diffs {
+		n.last = d.ToVersion
+		if d.FromVersion == 0 {
+			// The initial load after a cold start is not a change.
+			continue
+		}
+
+		body, err := json.Marshal(newPayload(d, n.cfg.MaxEntries))
+		if err != nil {
+			log.Printf("webhook: encode payload for version %d: %v", d.ToVersion, err)
+			continue
+		}
+		for _, target := range n.cfg.Targets {
+			dl := &delivery{
+				ID:          fmt.Sprintf("%d-%d", d.ToVersion, d.At.UnixNano()),
+				Target:      target,
+				Body:        body,
+				NextAttempt: time.Now(),
+			}
+			if err := n.queue.push(dl); err != nil {
+				log.Printf("webhook: %v", err)
+			}
+		}
+	}
+}
+
+// deliverDue tries every delivery that is due and returns how long to
+// wait for the next one, false if the queue is empty. Deliveries to one
+// target go out in order: a failed one holds back the later ones.
+func (n *Notifier) deliverDue(ctx context.Context) (time.Duration, bool) {
+	blocked := make(map[string]bool)
+	var next time.Time
+
+	for _, d := range append([]*delivery(nil), n.queue.items...) {
+		if blocked[d.Target] {
+			continue
+		}
+		blocked[d.Target] = true // until this one is delivered
+
+		if now := time.Now(); d.NextAttempt.After(now) {
+			if next.IsZero() || d.NextAttempt.Before(next) {
+				next = d.NextAttempt
+			}
+			continue
+		}
+
+		err := n.post(ctx, d)
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		if err == nil {
+			blocked[d.Target] = false
+			if err := n.queue.remove(d); err != nil {
+				log.Printf("webhook: %v", err)
+			}
+			continue
+		}
+
+		d.Attempts++
+		if d.Attempts >= n.cfg.MaxAttempts {
+			log.Printf("webhook: dropping delivery %s to %s after %d attempts: %v", d.ID, d.Target, d.Attempts, err)
+			blocked[d.Target] = false
+			if err := n.queue.remove(d); err != nil {
+				log.Printf("webhook: %v", err)
+			}
+			continue
+		}
+
+		d.NextAttempt = time.Now().Add(n.backoff(d.Attempts))
+		log.Printf("webhook: delivery %s to %s failed (attempt #%d), retry at %s: %v",
+			d.ID, d.Target, d.Attempts, d.NextAttempt.Format(time.RFC3339), err)
+		if err := n.queue.save(d); err != nil {
+			log.Printf("webhook: %v", err)
+		}
+		if next.IsZero() || d.NextAttempt.Before(next) {
+			next = d.NextAttempt
+		}
+	}
+
+	if next.IsZero() {
+		return 0, false
+	}
+	return max(time.Until(next), 0), true
+}
+
+func (n *Notifier) post(ctx context.Context, d *delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.Target, bytes.NewReader(d.Body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, d.ID)
+	if n.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign([]byte(n.cfg.Secret), d.Body))
+	}
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+func (n *Notifier) backoff(attempts int) time.Duration {
+	d := n.cfg.InitialBackoff << (attempts - 1)
+	if d <= 0 || d > n.cfg.MaxBackoff {
+		d = n.cfg.MaxBackoff
+	}
+	return d
+}
@@ -0,0 +1,212 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"evil-rkn/internal/domain"
+	"evil-rkn/internal/registry"
+)
+
+// receiver records the payloads posted to it, failing the first fail ones.
+type receiver struct {
+	mu       sync.Mutex
+	fail     int
+	payloads []Payload
+	headers  []http.Header
+	bodies   [][]byte
+	got      chan struct{}
+}
+
+func newReceiver(t *testing.T, fail int) (*receiver, *httptest.Server) {
+	r := &receiver{fail: fail, got: make(chan struct{}, 100)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.fail > 0 {
+			r.fail--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		var p Payload
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Errorf("bad payload %q: %v", body, err)
+		}
+		r.payloads = append(r.payloads, p)
+		r.headers = append(r.headers, req.Header.Clone())
+		r.bodies = append(r.bodies, body)
+		r.got <- struct{}{}
+	}))
+	t.Cleanup(srv.Close)
+	return r, srv
+}
+
+func (r *receiver) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.got:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d payloads, want %d", i, n)
+		}
+	}
+}
+
+func keyedRegistry(domains ...string) *domain.Registry {
+	hs := make([]uint64, len(domains))
+	for i, d := range domains {
+		hs[i] = domain.HashString64(d)
+	}
+	// Sorted by hash as the builder does; a handful of test domains
+	// is simplest to order with a plain insertion sort.
+	for i := 1; i < len(hs); i++ {
+		for j := i; j > 0 && hs[j] < hs[j-1]; j-- {
+			hs[j], hs[j-1] = hs[j-1], hs[j]
+			domains[j], domains[j-1] = domains[j-1], domains[j]
+		}
+	}
+	return &domain.Registry{DomainHashes: hs, DomainKeys: domain.NewKeyTable(domains), IPs: domain.NewIPSet()}
+}
+
+func runNotifier(t *testing.T, cfg Config, holder *registry.Holder) (stop func()) {
+	t.Helper()
+	n, err := NewNotifier(cfg, holder)
+	if err != nil {
+		t.Fatalf("NewNotifier error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = n.Run(ctx)
+		close(done)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestNotifier_DeliversSignedPayload(t *testing.T) {
+	rcv, srv := newReceiver(t, 0)
+	holder := registry.NewHolder()
+	holder.Set(keyedRegistry("kept.com", "removed.com")) // cold start, not notified
+
+	stop := runNotifier(t, Config{Targets: []string{srv.URL}, Secret: "s3cret"}, holder)
+	defer stop()
+
+	holder.Set(keyedRegistry("kept.com", "added.com"))
+	rcv.wait(t, 1)
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	p := rcv.payloads[0]
+	if p.Version != 2 || p.FromVersion != 1 || p.Summary {
+		t.Fatalf("payload = %+v, want 1 -> 2 with entries", p)
+	}
+	if len(p.Domains.Added) != 1 || p.Domains.Added[0] != "added.com" ||
+		len(p.Domains.Removed) != 1 || p.Domains.Removed[0] != "removed.com" {
+		t.Fatalf("domains = %+v, want +added.com -removed.com", p.Domains)
+	}
+	if got, want := rcv.headers[0].Get(SignatureHeader), Sign([]byte("s3cret"), rcv.bodies[0]); got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+	if rcv.headers[0].Get(DeliveryHeader) == "" {
+		t.Fatal("delivery id header is missing")
+	}
+}
+
+func TestNotifier_RetriesInOrder(t *testing.T) {
+	rcv, srv := newReceiver(t, 2)
+	holder := registry.NewHolder()
+	holder.Set(keyedRegistry("a.com"))
+
+	stop := runNotifier(t, Config{
+		Targets:        []string{srv.URL},
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+	}, holder)
+	defer stop()
+
+	holder.Set(keyedRegistry("a.com", "b.com"))
+	holder.Set(keyedRegistry("a.com", "b.com", "c.com"))
+	rcv.wait(t, 2)
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	if rcv.payloads[0].Version != 2 || rcv.payloads[1].Version != 3 {
+		t.Fatalf("versions %d, %d, want 2, 3 in order", rcv.payloads[0].Version, rcv.payloads[1].Version)
+	}
+}
+
+func TestNotifier_QueueSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	rcv, srv := newReceiver(t, 1)
+	holder := registry.NewHolder()
+	holder.Set(keyedRegistry("a.com"))
+	cfg := Config{Targets: []string{srv.URL}, QueueDir: dir, InitialBackoff: time.Millisecond}
+
+	// The first attempt fails: the delivery stays queued on disk.
+	n, err := NewNotifier(cfg, holder)
+	if err != nil {
+		t.Fatalf("NewNotifier error: %v", err)
+	}
+	holder.Set(keyedRegistry("a.com", "b.com"))
+	n.enqueue()
+	n.deliverDue(context.Background())
+	n.unsubscribe()
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("queue dir has %d entries, want 1", len(entries))
+	}
+
+	// After a restart the pending delivery is retried.
+	time.Sleep(5 * time.Millisecond)
+	n, err = NewNotifier(cfg, holder)
+	if err != nil {
+		t.Fatalf("NewNotifier error: %v", err)
+	}
+	if wait, ok := n.deliverDue(context.Background()); ok {
+		t.Fatalf("queue not drained, next attempt in %s", wait)
+	}
+	n.unsubscribe()
+
+	rcv.wait(t, 1)
+	if rcv.payloads[0].Version != 2 {
+		t.Fatalf("version = %d, want 2", rcv.payloads[0].Version)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("queue dir has %d entries after delivery, want 0", len(entries))
+	}
+}
+
+func TestNewPayload_Summary(t *testing.T) {
+	d := registry.Diff{
+		FromVersion: 1,
+		ToVersion:   2,
+		Domains:     registry.KindDiff{Added: []string{"a.com", "b.com"}, AddedCount: 2},
+		IPs:         registry.KindDiff{Removed: []string{"192.0.2.1"}, RemovedCount: 1},
+	}
+
+	if p := newPayload(d, 3); p.Summary || len(p.Domains.Added) != 2 {
+		t.Fatalf("payload = %+v, want entries listed", p)
+	}
+
+	p := newPayload(d, 2)
+	if !p.Summary || p.Domains.Added != nil || p.Domains.AddedCount != 2 || p.IPs.RemovedCount != 1 {
+		t.Fatalf("payload = %+v, want counts only", p)
+	}
+
+	// Entries the diff could not list force a summary too.
+	d.URLs = registry.KindDiff{AddedCount: 5}
+	if p := newPayload(d, 100); !p.Summary {
+		t.Fatalf("payload = %+v, want summary", p)
+	}
+}
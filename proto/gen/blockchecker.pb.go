@@ -10,6 +10,8 @@ import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,28 +24,1577 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Kind of registry rule that blocked the URL.
+type MatchKind int32
+
+const (
+	MatchKind_MATCH_KIND_NONE          MatchKind = 0
+	MatchKind_MATCH_KIND_IP            MatchKind = 1 // address or subnet entry
+	MatchKind_MATCH_KIND_URL           MatchKind = 2 // http URL entry
+	MatchKind_MATCH_KIND_HTTPS_HOST    MatchKind = 3 // host of an https URL entry
+	MatchKind_MATCH_KIND_DOMAIN        MatchKind = 4 // exact domain entry
+	MatchKind_MATCH_KIND_PARENT_DOMAIN MatchKind = 5 // parent domain entry
+)
+
+// Enum value maps for MatchKind.
+var (
+	MatchKind_name = map[int32]string{
+		0: "MATCH_KIND_NONE",
+		1: "MATCH_KIND_IP",
+		2: "MATCH_KIND_URL",
+		3: "MATCH_KIND_HTTPS_HOST",
+		4: "MATCH_KIND_DOMAIN",
+		5: "MATCH_KIND_PARENT_DOMAIN",
+	}
+	MatchKind_value = map[string]int32{
+		"MATCH_KIND_NONE":          0,
+		"MATCH_KIND_IP":            1,
+		"MATCH_KIND_URL":           2,
+		"MATCH_KIND_HTTPS_HOST":    3,
+		"MATCH_KIND_DOMAIN":        4,
+		"MATCH_KIND_PARENT_DOMAIN": 5,
+	}
+)
+
+func (x MatchKind) Enum() *MatchKind {
+	p := new(MatchKind)
+	*p = x
+	return p
+}
+
+func (x MatchKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MatchKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_blockchecker_proto_enumTypes[0].Descriptor()
+}
+
+func (MatchKind) Type() protoreflect.EnumType {
+	return &file_blockchecker_proto_enumTypes[0]
+}
+
+func (x MatchKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MatchKind.Descriptor instead.
+func (MatchKind) EnumDescriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{0}
+}
+
 type CheckRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Url   string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Fill the optional match details in the response.
+	Explain bool `protobuf:"varint,2,opt,name=explain,proto3" json:"explain,omitempty"`
+	// Accept URLs without a scheme ("example.com/path", "example.com:8080")
+	// and check them as http URLs.
+	Lenient       bool `protobuf:"varint,3,opt,name=lenient,proto3" json:"lenient,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckRequest) Reset() {
+	*x = CheckRequest{}
+	mi := &file_blockchecker_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckRequest) ProtoMessage() {}
+
+func (x *CheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckRequest.ProtoReflect.Descriptor instead.
+func (*CheckRequest) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CheckRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CheckRequest) GetExplain() bool {
+	if x != nil {
+		return x.Explain
+	}
+	return false
+}
+
+func (x *CheckRequest) GetLenient() bool {
+	if x != nil {
+		return x.Lenient
+	}
+	return false
+}
+
+type CheckHostRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Bare host name or IP literal, a port is ignored.
+	Host          string `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Explain       bool   `protobuf:"varint,2,opt,name=explain,proto3" json:"explain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckHostRequest) Reset() {
+	*x = CheckHostRequest{}
+	mi := &file_blockchecker_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckHostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckHostRequest) ProtoMessage() {}
+
+func (x *CheckHostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckHostRequest.ProtoReflect.Descriptor instead.
+func (*CheckHostRequest) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CheckHostRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *CheckHostRequest) GetExplain() bool {
+	if x != nil {
+		return x.Explain
+	}
+	return false
+}
+
+type CheckIPRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// IPv4 or IPv6 address.
+	Ip            string `protobuf:"bytes,1,opt,name=ip,proto3" json:"ip,omitempty"`
+	Explain       bool   `protobuf:"varint,2,opt,name=explain,proto3" json:"explain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckIPRequest) Reset() {
+	*x = CheckIPRequest{}
+	mi := &file_blockchecker_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckIPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckIPRequest) ProtoMessage() {}
+
+func (x *CheckIPRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckIPRequest.ProtoReflect.Descriptor instead.
+func (*CheckIPRequest) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CheckIPRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *CheckIPRequest) GetExplain() bool {
+	if x != nil {
+		return x.Explain
+	}
+	return false
+}
+
+type CheckResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Blocked bool                   `protobuf:"varint,1,opt,name=blocked,proto3" json:"blocked,omitempty"`
+	// Set only when the request asks to explain.
+	MatchKind *MatchKind `protobuf:"varint,2,opt,name=match_kind,json=matchKind,proto3,enum=blockchecker.v1.MatchKind,oneof" json:"match_kind,omitempty"`
+	// Registry key that matched, e.g. "blocked.com" for "sub.blocked.com".
+	MatchedRule *string `protobuf:"bytes,3,opt,name=matched_rule,json=matchedRule,proto3,oneof" json:"matched_rule,omitempty"`
+	// Normalized form of the URL, host or IP that was checked.
+	NormalizedUrl *string `protobuf:"bytes,4,opt,name=normalized_url,json=normalizedUrl,proto3,oneof" json:"normalized_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckResponse) Reset() {
+	*x = CheckResponse{}
+	mi := &file_blockchecker_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckResponse) ProtoMessage() {}
+
+func (x *CheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckResponse.ProtoReflect.Descriptor instead.
+func (*CheckResponse) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CheckResponse) GetBlocked() bool {
+	if x != nil {
+		return x.Blocked
+	}
+	return false
+}
+
+func (x *CheckResponse) GetMatchKind() MatchKind {
+	if x != nil && x.MatchKind != nil {
+		return *x.MatchKind
+	}
+	return MatchKind_MATCH_KIND_NONE
+}
+
+func (x *CheckResponse) GetMatchedRule() string {
+	if x != nil && x.MatchedRule != nil {
+		return *x.MatchedRule
+	}
+	return ""
+}
+
+func (x *CheckResponse) GetNormalizedUrl() string {
+	if x != nil && x.NormalizedUrl != nil {
+		return *x.NormalizedUrl
+	}
+	return ""
+}
+
+type CheckBatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// At most 1000 URLs.
+	Urls []string `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	// Fill the optional match details in the results.
+	Explain       bool `protobuf:"varint,2,opt,name=explain,proto3" json:"explain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckBatchRequest) Reset() {
+	*x = CheckBatchRequest{}
+	mi := &file_blockchecker_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckBatchRequest) ProtoMessage() {}
+
+func (x *CheckBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckBatchRequest.ProtoReflect.Descriptor instead.
+func (*CheckBatchRequest) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CheckBatchRequest) GetUrls() []string {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+func (x *CheckBatchRequest) GetExplain() bool {
+	if x != nil {
+		return x.Explain
+	}
+	return false
+}
+
+type CheckBatchItem struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// As given in the request.
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Why the URL could not be checked; result is absent then.
+	Error         string         `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Result        *CheckResponse `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckBatchItem) Reset() {
+	*x = CheckBatchItem{}
+	mi := &file_blockchecker_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckBatchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckBatchItem) ProtoMessage() {}
+
+func (x *CheckBatchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckBatchItem.ProtoReflect.Descriptor instead.
+func (*CheckBatchItem) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CheckBatchItem) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CheckBatchItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CheckBatchItem) GetResult() *CheckResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type CheckBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// In request order.
+	Items []*CheckBatchItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// All URLs are checked against this registry.
+	RegistryVersion     uint64 `protobuf:"varint,2,opt,name=registry_version,json=registryVersion,proto3" json:"registry_version,omitempty"`
+	RegistryFingerprint string `protobuf:"bytes,3,opt,name=registry_fingerprint,json=registryFingerprint,proto3" json:"registry_fingerprint,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CheckBatchResponse) Reset() {
+	*x = CheckBatchResponse{}
+	mi := &file_blockchecker_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckBatchResponse) ProtoMessage() {}
+
+func (x *CheckBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckBatchResponse.ProtoReflect.Descriptor instead.
+func (*CheckBatchResponse) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CheckBatchResponse) GetItems() []*CheckBatchItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CheckBatchResponse) GetRegistryVersion() uint64 {
+	if x != nil {
+		return x.RegistryVersion
+	}
+	return 0
+}
+
+func (x *CheckBatchResponse) GetRegistryFingerprint() string {
+	if x != nil {
+		return x.RegistryFingerprint
+	}
+	return ""
+}
+
+type CheckStreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Chosen by the client, echoed in the result.
+	Id  string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Url string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// Fill the optional match details in the result.
+	Explain       bool `protobuf:"varint,3,opt,name=explain,proto3" json:"explain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckStreamRequest) Reset() {
+	*x = CheckStreamRequest{}
+	mi := &file_blockchecker_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckStreamRequest) ProtoMessage() {}
+
+func (x *CheckStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckStreamRequest.ProtoReflect.Descriptor instead.
+func (*CheckStreamRequest) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CheckStreamRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CheckStreamRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CheckStreamRequest) GetExplain() bool {
+	if x != nil {
+		return x.Explain
+	}
+	return false
+}
+
+type CheckStreamResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Why the URL could not be checked; result is absent then.
+	Error         string         `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Result        *CheckResponse `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckStreamResult) Reset() {
+	*x = CheckStreamResult{}
+	mi := &file_blockchecker_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckStreamResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckStreamResult) ProtoMessage() {}
+
+func (x *CheckStreamResult) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckStreamResult.ProtoReflect.Descriptor instead.
+func (*CheckStreamResult) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CheckStreamResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CheckStreamResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CheckStreamResult) GetResult() *CheckResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// Registry the following results are checked against.
+type RegistryMarker struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       uint64                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Fingerprint   string                 `protobuf:"bytes,2,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegistryMarker) Reset() {
+	*x = RegistryMarker{}
+	mi := &file_blockchecker_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegistryMarker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryMarker) ProtoMessage() {}
+
+func (x *RegistryMarker) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryMarker.ProtoReflect.Descriptor instead.
+func (*RegistryMarker) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RegistryMarker) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RegistryMarker) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+type CheckStreamResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*CheckStreamResponse_Result
+	//	*CheckStreamResponse_Registry
+	Event         isCheckStreamResponse_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckStreamResponse) Reset() {
+	*x = CheckStreamResponse{}
+	mi := &file_blockchecker_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckStreamResponse) ProtoMessage() {}
+
+func (x *CheckStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckStreamResponse.ProtoReflect.Descriptor instead.
+func (*CheckStreamResponse) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CheckStreamResponse) GetEvent() isCheckStreamResponse_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *CheckStreamResponse) GetResult() *CheckStreamResult {
+	if x != nil {
+		if x, ok := x.Event.(*CheckStreamResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+func (x *CheckStreamResponse) GetRegistry() *RegistryMarker {
+	if x != nil {
+		if x, ok := x.Event.(*CheckStreamResponse_Registry); ok {
+			return x.Registry
+		}
+	}
+	return nil
+}
+
+type isCheckStreamResponse_Event interface {
+	isCheckStreamResponse_Event()
+}
+
+type CheckStreamResponse_Result struct {
+	Result *CheckStreamResult `protobuf:"bytes,1,opt,name=result,proto3,oneof"`
+}
+
+type CheckStreamResponse_Registry struct {
+	// Sent before the first result and whenever the registry changes.
+	Registry *RegistryMarker `protobuf:"bytes,2,opt,name=registry,proto3,oneof"`
+}
+
+func (*CheckStreamResponse_Result) isCheckStreamResponse_Event() {}
+
+func (*CheckStreamResponse_Registry) isCheckStreamResponse_Event() {}
+
+type GetRegistryDiffsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only diffs to versions newer than this one, 0 for all kept.
+	SinceVersion uint64 `protobuf:"varint,1,opt,name=since_version,json=sinceVersion,proto3" json:"since_version,omitempty"`
+	// At most this many most recent diffs, 0 for all kept.
+	Limit         uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegistryDiffsRequest) Reset() {
+	*x = GetRegistryDiffsRequest{}
+	mi := &file_blockchecker_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegistryDiffsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistryDiffsRequest) ProtoMessage() {}
+
+func (x *GetRegistryDiffsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistryDiffsRequest.ProtoReflect.Descriptor instead.
+func (*GetRegistryDiffsRequest) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetRegistryDiffsRequest) GetSinceVersion() uint64 {
+	if x != nil {
+		return x.SinceVersion
+	}
+	return 0
+}
+
+func (x *GetRegistryDiffsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Entries of one kind added and removed by an update.
+type EntryDiff struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Added        []string               `protobuf:"bytes,1,rep,name=added,proto3" json:"added,omitempty"`
+	Removed      []string               `protobuf:"bytes,2,rep,name=removed,proto3" json:"removed,omitempty"`
+	AddedCount   uint64                 `protobuf:"varint,3,opt,name=added_count,json=addedCount,proto3" json:"added_count,omitempty"`
+	RemovedCount uint64                 `protobuf:"varint,4,opt,name=removed_count,json=removedCount,proto3" json:"removed_count,omitempty"`
+	// Only the first entries are listed, the counts are exact.
+	Truncated     bool `protobuf:"varint,5,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntryDiff) Reset() {
+	*x = EntryDiff{}
+	mi := &file_blockchecker_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryDiff) ProtoMessage() {}
+
+func (x *EntryDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryDiff.ProtoReflect.Descriptor instead.
+func (*EntryDiff) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *EntryDiff) GetAdded() []string {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *EntryDiff) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *EntryDiff) GetAddedCount() uint64 {
+	if x != nil {
+		return x.AddedCount
+	}
+	return 0
+}
+
+func (x *EntryDiff) GetRemovedCount() uint64 {
+	if x != nil {
+		return x.RemovedCount
+	}
+	return 0
+}
+
+func (x *EntryDiff) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type RegistryDiff struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromVersion   uint64                 `protobuf:"varint,1,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	ToVersion     uint64                 `protobuf:"varint,2,opt,name=to_version,json=toVersion,proto3" json:"to_version,omitempty"`
+	AppliedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=applied_at,json=appliedAt,proto3" json:"applied_at,omitempty"`
+	Domains       *EntryDiff             `protobuf:"bytes,4,opt,name=domains,proto3" json:"domains,omitempty"`
+	Urls          *EntryDiff             `protobuf:"bytes,5,opt,name=urls,proto3" json:"urls,omitempty"`                               // http URL entries
+	HttpsHosts    *EntryDiff             `protobuf:"bytes,6,opt,name=https_hosts,json=httpsHosts,proto3" json:"https_hosts,omitempty"` // hosts of https URL entries
+	Ips           *EntryDiff             `protobuf:"bytes,7,opt,name=ips,proto3" json:"ips,omitempty"`                                 // single addresses
+	Subnets       *EntryDiff             `protobuf:"bytes,8,opt,name=subnets,proto3" json:"subnets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegistryDiff) Reset() {
+	*x = RegistryDiff{}
+	mi := &file_blockchecker_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegistryDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryDiff) ProtoMessage() {}
+
+func (x *RegistryDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryDiff.ProtoReflect.Descriptor instead.
+func (*RegistryDiff) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RegistryDiff) GetFromVersion() uint64 {
+	if x != nil {
+		return x.FromVersion
+	}
+	return 0
+}
+
+func (x *RegistryDiff) GetToVersion() uint64 {
+	if x != nil {
+		return x.ToVersion
+	}
+	return 0
+}
+
+func (x *RegistryDiff) GetAppliedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AppliedAt
+	}
+	return nil
+}
+
+func (x *RegistryDiff) GetDomains() *EntryDiff {
+	if x != nil {
+		return x.Domains
+	}
+	return nil
+}
+
+func (x *RegistryDiff) GetUrls() *EntryDiff {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+func (x *RegistryDiff) GetHttpsHosts() *EntryDiff {
+	if x != nil {
+		return x.HttpsHosts
+	}
+	return nil
+}
+
+func (x *RegistryDiff) GetIps() *EntryDiff {
+	if x != nil {
+		return x.Ips
+	}
+	return nil
+}
+
+func (x *RegistryDiff) GetSubnets() *EntryDiff {
+	if x != nil {
+		return x.Subnets
+	}
+	return nil
+}
+
+type GetRegistryDiffsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CurrentVersion uint64                 `protobuf:"varint,1,opt,name=current_version,json=currentVersion,proto3" json:"current_version,omitempty"`
+	// Oldest first.
+	Diffs         []*RegistryDiff `protobuf:"bytes,2,rep,name=diffs,proto3" json:"diffs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegistryDiffsResponse) Reset() {
+	*x = GetRegistryDiffsResponse{}
+	mi := &file_blockchecker_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegistryDiffsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistryDiffsResponse) ProtoMessage() {}
+
+func (x *GetRegistryDiffsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistryDiffsResponse.ProtoReflect.Descriptor instead.
+func (*GetRegistryDiffsResponse) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetRegistryDiffsResponse) GetCurrentVersion() uint64 {
+	if x != nil {
+		return x.CurrentVersion
+	}
+	return 0
+}
+
+func (x *GetRegistryDiffsResponse) GetDiffs() []*RegistryDiff {
+	if x != nil {
+		return x.Diffs
+	}
+	return nil
+}
+
+type WatchRegistryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Resume after this version: the missed diffs are replayed while the
+	// server still keeps them. 0 starts with the current version.
+	FromVersion   uint64 `protobuf:"varint,1,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRegistryRequest) Reset() {
+	*x = WatchRegistryRequest{}
+	mi := &file_blockchecker_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRegistryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRegistryRequest) ProtoMessage() {}
+
+func (x *WatchRegistryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRegistryRequest.ProtoReflect.Descriptor instead.
+func (*WatchRegistryRequest) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *WatchRegistryRequest) GetFromVersion() uint64 {
+	if x != nil {
+		return x.FromVersion
+	}
+	return 0
+}
+
+type RegistryEvent struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Version     uint64                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	LastUpdated *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=last_updated,json=lastUpdated,proto3" json:"last_updated,omitempty"`
+	// What changed since the previous event; absent in the first event of
+	// a fresh subscription and in resync events.
+	Diff *RegistryDiff `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"`
+	// The versions since from_version can no longer be replayed: the
+	// client has to reload its full state.
+	Resync        bool `protobuf:"varint,4,opt,name=resync,proto3" json:"resync,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegistryEvent) Reset() {
+	*x = RegistryEvent{}
+	mi := &file_blockchecker_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegistryEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryEvent) ProtoMessage() {}
+
+func (x *RegistryEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryEvent.ProtoReflect.Descriptor instead.
+func (*RegistryEvent) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RegistryEvent) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RegistryEvent) GetLastUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUpdated
+	}
+	return nil
+}
+
+func (x *RegistryEvent) GetDiff() *RegistryDiff {
+	if x != nil {
+		return x.Diff
+	}
+	return nil
+}
+
+func (x *RegistryEvent) GetResync() bool {
+	if x != nil {
+		return x.Resync
+	}
+	return false
+}
+
+type GetRegistryStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// At most this many most recent reports, 0 for all kept.
+	Limit         uint32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegistryStatusRequest) Reset() {
+	*x = GetRegistryStatusRequest{}
+	mi := &file_blockchecker_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegistryStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistryStatusRequest) ProtoMessage() {}
+
+func (x *GetRegistryStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistryStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetRegistryStatusRequest) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetRegistryStatusRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Entries of one kind dropped while building a registry.
+type SkipCounts struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Empty uint64                 `protobuf:"varint,1,opt,name=empty,proto3" json:"empty,omitempty"`
+	// Domains with '_' in name.
+	Underscore uint64 `protobuf:"varint,2,opt,name=underscore,proto3" json:"underscore,omitempty"`
+	// Entries rejected by the normalizer.
+	Invalid       uint64 `protobuf:"varint,3,opt,name=invalid,proto3" json:"invalid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkipCounts) Reset() {
+	*x = SkipCounts{}
+	mi := &file_blockchecker_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkipCounts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkipCounts) ProtoMessage() {}
+
+func (x *SkipCounts) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkipCounts.ProtoReflect.Descriptor instead.
+func (*SkipCounts) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SkipCounts) GetEmpty() uint64 {
+	if x != nil {
+		return x.Empty
+	}
+	return 0
+}
+
+func (x *SkipCounts) GetUnderscore() uint64 {
+	if x != nil {
+		return x.Underscore
+	}
+	return 0
+}
+
+func (x *SkipCounts) GetInvalid() uint64 {
+	if x != nil {
+		return x.Invalid
+	}
+	return 0
+}
+
+// One registry update attempt.
+type UpdateReport struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// What started it, e.g. "update" or "requested update".
+	Trigger    string                 `protobuf:"bytes,1,opt,name=trigger,proto3" json:"trigger,omitempty"`
+	StartedAt  *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Duration   *durationpb.Duration   `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	// Empty if the update succeeded.
+	Error string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	// The source had not changed, the current registry was kept.
+	NotModified bool `protobuf:"varint,6,opt,name=not_modified,json=notModified,proto3" json:"not_modified,omitempty"`
+	// Registry served after the update; zero if it failed.
+	Version    uint64 `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	Domains    uint64 `protobuf:"varint,8,opt,name=domains,proto3" json:"domains,omitempty"`
+	Urls       uint64 `protobuf:"varint,9,opt,name=urls,proto3" json:"urls,omitempty"`
+	HttpsHosts uint64 `protobuf:"varint,10,opt,name=https_hosts,json=httpsHosts,proto3" json:"https_hosts,omitempty"`
+	Ips        uint64 `protobuf:"varint,11,opt,name=ips,proto3" json:"ips,omitempty"`
+	// What this attempt read. Source and byte counts are set for failed
+	// and not modified attempts too, the latter transfer nothing. The
+	// rest describes a registry built by the attempt, also one rejected
+	// by the sanity rules.
+	Source            string      `protobuf:"bytes,12,opt,name=source,proto3" json:"source,omitempty"`
+	CompressedBytes   uint64      `protobuf:"varint,13,opt,name=compressed_bytes,json=compressedBytes,proto3" json:"compressed_bytes,omitempty"`
+	DecompressedBytes uint64      `protobuf:"varint,14,opt,name=decompressed_bytes,json=decompressedBytes,proto3" json:"decompressed_bytes,omitempty"`
+	SkippedDomains    *SkipCounts `protobuf:"bytes,15,opt,name=skipped_domains,json=skippedDomains,proto3" json:"skipped_domains,omitempty"`
+	SkippedUrls       *SkipCounts `protobuf:"bytes,16,opt,name=skipped_urls,json=skippedUrls,proto3" json:"skipped_urls,omitempty"`
+	SkippedIps        *SkipCounts `protobuf:"bytes,17,opt,name=skipped_ips,json=skippedIps,proto3" json:"skipped_ips,omitempty"`
+	// Source records that could not be parsed at all.
+	Malformed uint64 `protobuf:"varint,18,opt,name=malformed,proto3" json:"malformed,omitempty"`
+	// Distinct keys sharing a 64-bit hash, resolved by string comparison.
+	Collisions uint64 `protobuf:"varint,19,opt,name=collisions,proto3" json:"collisions,omitempty"`
+	// First few accepted domains.
+	SampleDomains []string `protobuf:"bytes,20,rep,name=sample_domains,json=sampleDomains,proto3" json:"sample_domains,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateReport) Reset() {
+	*x = UpdateReport{}
+	mi := &file_blockchecker_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateReport) ProtoMessage() {}
+
+func (x *UpdateReport) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateReport.ProtoReflect.Descriptor instead.
+func (*UpdateReport) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UpdateReport) GetTrigger() string {
+	if x != nil {
+		return x.Trigger
+	}
+	return ""
+}
+
+func (x *UpdateReport) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *UpdateReport) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *UpdateReport) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *UpdateReport) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *UpdateReport) GetNotModified() bool {
+	if x != nil {
+		return x.NotModified
+	}
+	return false
+}
+
+func (x *UpdateReport) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UpdateReport) GetDomains() uint64 {
+	if x != nil {
+		return x.Domains
+	}
+	return 0
+}
+
+func (x *UpdateReport) GetUrls() uint64 {
+	if x != nil {
+		return x.Urls
+	}
+	return 0
+}
+
+func (x *UpdateReport) GetHttpsHosts() uint64 {
+	if x != nil {
+		return x.HttpsHosts
+	}
+	return 0
+}
+
+func (x *UpdateReport) GetIps() uint64 {
+	if x != nil {
+		return x.Ips
+	}
+	return 0
+}
+
+func (x *UpdateReport) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *UpdateReport) GetCompressedBytes() uint64 {
+	if x != nil {
+		return x.CompressedBytes
+	}
+	return 0
+}
+
+func (x *UpdateReport) GetDecompressedBytes() uint64 {
+	if x != nil {
+		return x.DecompressedBytes
+	}
+	return 0
+}
+
+func (x *UpdateReport) GetSkippedDomains() *SkipCounts {
+	if x != nil {
+		return x.SkippedDomains
+	}
+	return nil
+}
+
+func (x *UpdateReport) GetSkippedUrls() *SkipCounts {
+	if x != nil {
+		return x.SkippedUrls
+	}
+	return nil
+}
+
+func (x *UpdateReport) GetSkippedIps() *SkipCounts {
+	if x != nil {
+		return x.SkippedIps
+	}
+	return nil
+}
+
+func (x *UpdateReport) GetMalformed() uint64 {
+	if x != nil {
+		return x.Malformed
+	}
+	return 0
+}
+
+func (x *UpdateReport) GetCollisions() uint64 {
+	if x != nil {
+		return x.Collisions
+	}
+	return 0
+}
+
+func (x *UpdateReport) GetSampleDomains() []string {
+	if x != nil {
+		return x.SampleDomains
+	}
+	return nil
+}
+
+type GetRegistryStatusResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CurrentVersion uint64                 `protobuf:"varint,1,opt,name=current_version,json=currentVersion,proto3" json:"current_version,omitempty"`
+	LastUpdated    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=last_updated,json=lastUpdated,proto3" json:"last_updated,omitempty"`
+	NextUpdate     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=next_update,json=nextUpdate,proto3" json:"next_update,omitempty"`
+	// Consecutive failed attempts, 0 after a success.
+	FailedAttempts uint32                 `protobuf:"varint,4,opt,name=failed_attempts,json=failedAttempts,proto3" json:"failed_attempts,omitempty"`
+	LastSuccess    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_success,json=lastSuccess,proto3" json:"last_success,omitempty"`
+	// Of the last attempt, empty if it succeeded.
+	LastError string `protobuf:"bytes,6,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	// Oldest first.
+	Reports       []*UpdateReport `protobuf:"bytes,7,rep,name=reports,proto3" json:"reports,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegistryStatusResponse) Reset() {
+	*x = GetRegistryStatusResponse{}
+	mi := &file_blockchecker_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegistryStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistryStatusResponse) ProtoMessage() {}
+
+func (x *GetRegistryStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistryStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetRegistryStatusResponse) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetRegistryStatusResponse) GetCurrentVersion() uint64 {
+	if x != nil {
+		return x.CurrentVersion
+	}
+	return 0
+}
+
+func (x *GetRegistryStatusResponse) GetLastUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUpdated
+	}
+	return nil
+}
+
+func (x *GetRegistryStatusResponse) GetNextUpdate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextUpdate
+	}
+	return nil
+}
+
+func (x *GetRegistryStatusResponse) GetFailedAttempts() uint32 {
+	if x != nil {
+		return x.FailedAttempts
+	}
+	return 0
+}
+
+func (x *GetRegistryStatusResponse) GetLastSuccess() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSuccess
+	}
+	return nil
+}
+
+func (x *GetRegistryStatusResponse) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *GetRegistryStatusResponse) GetReports() []*UpdateReport {
+	if x != nil {
+		return x.Reports
+	}
+	return nil
+}
+
+type GetRegistryInfoRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CheckRequest) Reset() {
-	*x = CheckRequest{}
-	mi := &file_blockchecker_proto_msgTypes[0]
+func (x *GetRegistryInfoRequest) Reset() {
+	*x = GetRegistryInfoRequest{}
+	mi := &file_blockchecker_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CheckRequest) String() string {
+func (x *GetRegistryInfoRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CheckRequest) ProtoMessage() {}
+func (*GetRegistryInfoRequest) ProtoMessage() {}
 
-func (x *CheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_blockchecker_proto_msgTypes[0]
+func (x *GetRegistryInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -54,40 +1605,142 @@ func (x *CheckRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CheckRequest.ProtoReflect.Descriptor instead.
-func (*CheckRequest) Descriptor() ([]byte, []int) {
-	return file_blockchecker_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use GetRegistryInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetRegistryInfoRequest) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *CheckRequest) GetUrl() string {
+type GetRegistryInfoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Local to this replica: every accepted update gets the next number.
+	Version uint64 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// Digest of the registry content, equal on replicas serving the same data.
+	Fingerprint   string                 `protobuf:"bytes,2,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	LastUpdated   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_updated,json=lastUpdated,proto3" json:"last_updated,omitempty"`
+	Source        string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	Domains       uint64                 `protobuf:"varint,5,opt,name=domains,proto3" json:"domains,omitempty"`
+	Urls          uint64                 `protobuf:"varint,6,opt,name=urls,proto3" json:"urls,omitempty"`                               // http URL entries
+	HttpsHosts    uint64                 `protobuf:"varint,7,opt,name=https_hosts,json=httpsHosts,proto3" json:"https_hosts,omitempty"` // hosts of https URL entries
+	Ips           uint64                 `protobuf:"varint,8,opt,name=ips,proto3" json:"ips,omitempty"`                                 // single addresses
+	Subnets       uint64                 `protobuf:"varint,9,opt,name=subnets,proto3" json:"subnets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegistryInfoResponse) Reset() {
+	*x = GetRegistryInfoResponse{}
+	mi := &file_blockchecker_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegistryInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistryInfoResponse) ProtoMessage() {}
+
+func (x *GetRegistryInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[22]
 	if x != nil {
-		return x.Url
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistryInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetRegistryInfoResponse) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetRegistryInfoResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *GetRegistryInfoResponse) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
 	}
 	return ""
 }
 
-type CheckResponse struct {
+func (x *GetRegistryInfoResponse) GetLastUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUpdated
+	}
+	return nil
+}
+
+func (x *GetRegistryInfoResponse) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *GetRegistryInfoResponse) GetDomains() uint64 {
+	if x != nil {
+		return x.Domains
+	}
+	return 0
+}
+
+func (x *GetRegistryInfoResponse) GetUrls() uint64 {
+	if x != nil {
+		return x.Urls
+	}
+	return 0
+}
+
+func (x *GetRegistryInfoResponse) GetHttpsHosts() uint64 {
+	if x != nil {
+		return x.HttpsHosts
+	}
+	return 0
+}
+
+func (x *GetRegistryInfoResponse) GetIps() uint64 {
+	if x != nil {
+		return x.Ips
+	}
+	return 0
+}
+
+func (x *GetRegistryInfoResponse) GetSubnets() uint64 {
+	if x != nil {
+		return x.Subnets
+	}
+	return 0
+}
+
+type RefreshRegistryRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Blocked       bool                   `protobuf:"varint,1,opt,name=blocked,proto3" json:"blocked,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CheckResponse) Reset() {
-	*x = CheckResponse{}
-	mi := &file_blockchecker_proto_msgTypes[1]
+func (x *RefreshRegistryRequest) Reset() {
+	*x = RefreshRegistryRequest{}
+	mi := &file_blockchecker_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CheckResponse) String() string {
+func (x *RefreshRegistryRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CheckResponse) ProtoMessage() {}
+func (*RefreshRegistryRequest) ProtoMessage() {}
 
-func (x *CheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_blockchecker_proto_msgTypes[1]
+func (x *RefreshRegistryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -98,29 +1751,314 @@ func (x *CheckResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CheckResponse.ProtoReflect.Descriptor instead.
-func (*CheckResponse) Descriptor() ([]byte, []int) {
-	return file_blockchecker_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use RefreshRegistryRequest.ProtoReflect.Descriptor instead.
+func (*RefreshRegistryRequest) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *CheckResponse) GetBlocked() bool {
+type ForceAcceptRegistryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForceAcceptRegistryRequest) Reset() {
+	*x = ForceAcceptRegistryRequest{}
+	mi := &file_blockchecker_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForceAcceptRegistryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForceAcceptRegistryRequest) ProtoMessage() {}
+
+func (x *ForceAcceptRegistryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[24]
 	if x != nil {
-		return x.Blocked
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForceAcceptRegistryRequest.ProtoReflect.Descriptor instead.
+func (*ForceAcceptRegistryRequest) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{24}
+}
+
+// Outcome of an update run on request.
+type RegistryUpdateResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Version uint64                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// The source had not changed, the current registry was kept.
+	NotModified   bool   `protobuf:"varint,2,opt,name=not_modified,json=notModified,proto3" json:"not_modified,omitempty"`
+	Source        string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Domains       uint64 `protobuf:"varint,4,opt,name=domains,proto3" json:"domains,omitempty"`
+	Urls          uint64 `protobuf:"varint,5,opt,name=urls,proto3" json:"urls,omitempty"`
+	HttpsHosts    uint64 `protobuf:"varint,6,opt,name=https_hosts,json=httpsHosts,proto3" json:"https_hosts,omitempty"`
+	Ips           uint64 `protobuf:"varint,7,opt,name=ips,proto3" json:"ips,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegistryUpdateResponse) Reset() {
+	*x = RegistryUpdateResponse{}
+	mi := &file_blockchecker_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegistryUpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryUpdateResponse) ProtoMessage() {}
+
+func (x *RegistryUpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_blockchecker_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryUpdateResponse.ProtoReflect.Descriptor instead.
+func (*RegistryUpdateResponse) Descriptor() ([]byte, []int) {
+	return file_blockchecker_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RegistryUpdateResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RegistryUpdateResponse) GetNotModified() bool {
+	if x != nil {
+		return x.NotModified
 	}
 	return false
 }
 
+func (x *RegistryUpdateResponse) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *RegistryUpdateResponse) GetDomains() uint64 {
+	if x != nil {
+		return x.Domains
+	}
+	return 0
+}
+
+func (x *RegistryUpdateResponse) GetUrls() uint64 {
+	if x != nil {
+		return x.Urls
+	}
+	return 0
+}
+
+func (x *RegistryUpdateResponse) GetHttpsHosts() uint64 {
+	if x != nil {
+		return x.HttpsHosts
+	}
+	return 0
+}
+
+func (x *RegistryUpdateResponse) GetIps() uint64 {
+	if x != nil {
+		return x.Ips
+	}
+	return 0
+}
+
 var File_blockchecker_proto protoreflect.FileDescriptor
 
 const file_blockchecker_proto_rawDesc = "" +
 	"\n" +
-	"\x12blockchecker.proto\x12\x0fblockchecker.v1\x1a\x1cgoogle/api/annotations.proto\" \n" +
+	"\x12blockchecker.proto\x12\x0fblockchecker.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"T\n" +
 	"\fCheckRequest\x12\x10\n" +
-	"\x03url\x18\x01 \x01(\tR\x03url\")\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x18\n" +
+	"\aexplain\x18\x02 \x01(\bR\aexplain\x12\x18\n" +
+	"\alenient\x18\x03 \x01(\bR\alenient\"@\n" +
+	"\x10CheckHostRequest\x12\x12\n" +
+	"\x04host\x18\x01 \x01(\tR\x04host\x12\x18\n" +
+	"\aexplain\x18\x02 \x01(\bR\aexplain\":\n" +
+	"\x0eCheckIPRequest\x12\x0e\n" +
+	"\x02ip\x18\x01 \x01(\tR\x02ip\x12\x18\n" +
+	"\aexplain\x18\x02 \x01(\bR\aexplain\"\xf0\x01\n" +
 	"\rCheckResponse\x12\x18\n" +
-	"\ablocked\x18\x01 \x01(\bR\ablocked2\x81\x01\n" +
+	"\ablocked\x18\x01 \x01(\bR\ablocked\x12>\n" +
+	"\n" +
+	"match_kind\x18\x02 \x01(\x0e2\x1a.blockchecker.v1.MatchKindH\x00R\tmatchKind\x88\x01\x01\x12&\n" +
+	"\fmatched_rule\x18\x03 \x01(\tH\x01R\vmatchedRule\x88\x01\x01\x12*\n" +
+	"\x0enormalized_url\x18\x04 \x01(\tH\x02R\rnormalizedUrl\x88\x01\x01B\r\n" +
+	"\v_match_kindB\x0f\n" +
+	"\r_matched_ruleB\x11\n" +
+	"\x0f_normalized_url\"A\n" +
+	"\x11CheckBatchRequest\x12\x12\n" +
+	"\x04urls\x18\x01 \x03(\tR\x04urls\x12\x18\n" +
+	"\aexplain\x18\x02 \x01(\bR\aexplain\"p\n" +
+	"\x0eCheckBatchItem\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x126\n" +
+	"\x06result\x18\x03 \x01(\v2\x1e.blockchecker.v1.CheckResponseR\x06result\"\xa9\x01\n" +
+	"\x12CheckBatchResponse\x125\n" +
+	"\x05items\x18\x01 \x03(\v2\x1f.blockchecker.v1.CheckBatchItemR\x05items\x12)\n" +
+	"\x10registry_version\x18\x02 \x01(\x04R\x0fregistryVersion\x121\n" +
+	"\x14registry_fingerprint\x18\x03 \x01(\tR\x13registryFingerprint\"P\n" +
+	"\x12CheckStreamRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x18\n" +
+	"\aexplain\x18\x03 \x01(\bR\aexplain\"q\n" +
+	"\x11CheckStreamResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x126\n" +
+	"\x06result\x18\x03 \x01(\v2\x1e.blockchecker.v1.CheckResponseR\x06result\"L\n" +
+	"\x0eRegistryMarker\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x04R\aversion\x12 \n" +
+	"\vfingerprint\x18\x02 \x01(\tR\vfingerprint\"\x9b\x01\n" +
+	"\x13CheckStreamResponse\x12<\n" +
+	"\x06result\x18\x01 \x01(\v2\".blockchecker.v1.CheckStreamResultH\x00R\x06result\x12=\n" +
+	"\bregistry\x18\x02 \x01(\v2\x1f.blockchecker.v1.RegistryMarkerH\x00R\bregistryB\a\n" +
+	"\x05event\"T\n" +
+	"\x17GetRegistryDiffsRequest\x12#\n" +
+	"\rsince_version\x18\x01 \x01(\x04R\fsinceVersion\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\"\x9f\x01\n" +
+	"\tEntryDiff\x12\x14\n" +
+	"\x05added\x18\x01 \x03(\tR\x05added\x12\x18\n" +
+	"\aremoved\x18\x02 \x03(\tR\aremoved\x12\x1f\n" +
+	"\vadded_count\x18\x03 \x01(\x04R\n" +
+	"addedCount\x12#\n" +
+	"\rremoved_count\x18\x04 \x01(\x04R\fremovedCount\x12\x1c\n" +
+	"\ttruncated\x18\x05 \x01(\bR\ttruncated\"\x92\x03\n" +
+	"\fRegistryDiff\x12!\n" +
+	"\ffrom_version\x18\x01 \x01(\x04R\vfromVersion\x12\x1d\n" +
+	"\n" +
+	"to_version\x18\x02 \x01(\x04R\ttoVersion\x129\n" +
+	"\n" +
+	"applied_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tappliedAt\x124\n" +
+	"\adomains\x18\x04 \x01(\v2\x1a.blockchecker.v1.EntryDiffR\adomains\x12.\n" +
+	"\x04urls\x18\x05 \x01(\v2\x1a.blockchecker.v1.EntryDiffR\x04urls\x12;\n" +
+	"\vhttps_hosts\x18\x06 \x01(\v2\x1a.blockchecker.v1.EntryDiffR\n" +
+	"httpsHosts\x12,\n" +
+	"\x03ips\x18\a \x01(\v2\x1a.blockchecker.v1.EntryDiffR\x03ips\x124\n" +
+	"\asubnets\x18\b \x01(\v2\x1a.blockchecker.v1.EntryDiffR\asubnets\"x\n" +
+	"\x18GetRegistryDiffsResponse\x12'\n" +
+	"\x0fcurrent_version\x18\x01 \x01(\x04R\x0ecurrentVersion\x123\n" +
+	"\x05diffs\x18\x02 \x03(\v2\x1d.blockchecker.v1.RegistryDiffR\x05diffs\"9\n" +
+	"\x14WatchRegistryRequest\x12!\n" +
+	"\ffrom_version\x18\x01 \x01(\x04R\vfromVersion\"\xb3\x01\n" +
+	"\rRegistryEvent\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x04R\aversion\x12=\n" +
+	"\flast_updated\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vlastUpdated\x121\n" +
+	"\x04diff\x18\x03 \x01(\v2\x1d.blockchecker.v1.RegistryDiffR\x04diff\x12\x16\n" +
+	"\x06resync\x18\x04 \x01(\bR\x06resync\"0\n" +
+	"\x18GetRegistryStatusRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\rR\x05limit\"\\\n" +
+	"\n" +
+	"SkipCounts\x12\x14\n" +
+	"\x05empty\x18\x01 \x01(\x04R\x05empty\x12\x1e\n" +
+	"\n" +
+	"underscore\x18\x02 \x01(\x04R\n" +
+	"underscore\x12\x18\n" +
+	"\ainvalid\x18\x03 \x01(\x04R\ainvalid\"\xa6\x06\n" +
+	"\fUpdateReport\x12\x18\n" +
+	"\atrigger\x18\x01 \x01(\tR\atrigger\x129\n" +
+	"\n" +
+	"started_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x125\n" +
+	"\bduration\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12!\n" +
+	"\fnot_modified\x18\x06 \x01(\bR\vnotModified\x12\x18\n" +
+	"\aversion\x18\a \x01(\x04R\aversion\x12\x18\n" +
+	"\adomains\x18\b \x01(\x04R\adomains\x12\x12\n" +
+	"\x04urls\x18\t \x01(\x04R\x04urls\x12\x1f\n" +
+	"\vhttps_hosts\x18\n" +
+	" \x01(\x04R\n" +
+	"httpsHosts\x12\x10\n" +
+	"\x03ips\x18\v \x01(\x04R\x03ips\x12\x16\n" +
+	"\x06source\x18\f \x01(\tR\x06source\x12)\n" +
+	"\x10compressed_bytes\x18\r \x01(\x04R\x0fcompressedBytes\x12-\n" +
+	"\x12decompressed_bytes\x18\x0e \x01(\x04R\x11decompressedBytes\x12D\n" +
+	"\x0fskipped_domains\x18\x0f \x01(\v2\x1b.blockchecker.v1.SkipCountsR\x0eskippedDomains\x12>\n" +
+	"\fskipped_urls\x18\x10 \x01(\v2\x1b.blockchecker.v1.SkipCountsR\vskippedUrls\x12<\n" +
+	"\vskipped_ips\x18\x11 \x01(\v2\x1b.blockchecker.v1.SkipCountsR\n" +
+	"skippedIps\x12\x1c\n" +
+	"\tmalformed\x18\x12 \x01(\x04R\tmalformed\x12\x1e\n" +
+	"\n" +
+	"collisions\x18\x13 \x01(\x04R\n" +
+	"collisions\x12%\n" +
+	"\x0esample_domains\x18\x14 \x03(\tR\rsampleDomains\"\x80\x03\n" +
+	"\x19GetRegistryStatusResponse\x12'\n" +
+	"\x0fcurrent_version\x18\x01 \x01(\x04R\x0ecurrentVersion\x12=\n" +
+	"\flast_updated\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vlastUpdated\x12;\n" +
+	"\vnext_update\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"nextUpdate\x12'\n" +
+	"\x0ffailed_attempts\x18\x04 \x01(\rR\x0efailedAttempts\x12=\n" +
+	"\flast_success\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vlastSuccess\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x06 \x01(\tR\tlastError\x127\n" +
+	"\areports\x18\a \x03(\v2\x1d.blockchecker.v1.UpdateReportR\areports\"\x18\n" +
+	"\x16GetRegistryInfoRequest\"\xa7\x02\n" +
+	"\x17GetRegistryInfoResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x04R\aversion\x12 \n" +
+	"\vfingerprint\x18\x02 \x01(\tR\vfingerprint\x12=\n" +
+	"\flast_updated\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vlastUpdated\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x18\n" +
+	"\adomains\x18\x05 \x01(\x04R\adomains\x12\x12\n" +
+	"\x04urls\x18\x06 \x01(\x04R\x04urls\x12\x1f\n" +
+	"\vhttps_hosts\x18\a \x01(\x04R\n" +
+	"httpsHosts\x12\x10\n" +
+	"\x03ips\x18\b \x01(\x04R\x03ips\x12\x18\n" +
+	"\asubnets\x18\t \x01(\x04R\asubnets\"\x18\n" +
+	"\x16RefreshRegistryRequest\"\x1c\n" +
+	"\x1aForceAcceptRegistryRequest\"\xce\x01\n" +
+	"\x16RegistryUpdateResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x04R\aversion\x12!\n" +
+	"\fnot_modified\x18\x02 \x01(\bR\vnotModified\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x18\n" +
+	"\adomains\x18\x04 \x01(\x04R\adomains\x12\x12\n" +
+	"\x04urls\x18\x05 \x01(\x04R\x04urls\x12\x1f\n" +
+	"\vhttps_hosts\x18\x06 \x01(\x04R\n" +
+	"httpsHosts\x12\x10\n" +
+	"\x03ips\x18\a \x01(\x04R\x03ips*\x97\x01\n" +
+	"\tMatchKind\x12\x13\n" +
+	"\x0fMATCH_KIND_NONE\x10\x00\x12\x11\n" +
+	"\rMATCH_KIND_IP\x10\x01\x12\x12\n" +
+	"\x0eMATCH_KIND_URL\x10\x02\x12\x19\n" +
+	"\x15MATCH_KIND_HTTPS_HOST\x10\x03\x12\x15\n" +
+	"\x11MATCH_KIND_DOMAIN\x10\x04\x12\x1c\n" +
+	"\x18MATCH_KIND_PARENT_DOMAIN\x10\x052\xc0\b\n" +
 	"\fBlockChecker\x12q\n" +
-	"\x05Check\x12\x1d.blockchecker.v1.CheckRequest\x1a\x1e.blockchecker.v1.CheckResponse\")\x82\xd3\xe4\x93\x02#Z\x12:\x01*\"\r/api/v1/check\x12\r/api/v1/checkB$Z\"evil-rkn/proto/gen;blockcheckerpbbb\x06proto3"
+	"\x05Check\x12\x1d.blockchecker.v1.CheckRequest\x1a\x1e.blockchecker.v1.CheckResponse\")\x82\xd3\xe4\x93\x02#Z\x12:\x01*\"\r/api/v1/check\x12\r/api/v1/check\x12j\n" +
+	"\tCheckHost\x12!.blockchecker.v1.CheckHostRequest\x1a\x1e.blockchecker.v1.CheckResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/api/v1/check/host\x12d\n" +
+	"\aCheckIP\x12\x1f.blockchecker.v1.CheckIPRequest\x1a\x1e.blockchecker.v1.CheckResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/api/v1/check/ip\x12u\n" +
+	"\n" +
+	"CheckBatch\x12\".blockchecker.v1.CheckBatchRequest\x1a#.blockchecker.v1.CheckBatchResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/api/v1/check:batch\x12\\\n" +
+	"\vCheckStream\x12#.blockchecker.v1.CheckStreamRequest\x1a$.blockchecker.v1.CheckStreamResponse(\x010\x01\x12\x83\x01\n" +
+	"\x0fGetRegistryInfo\x12'.blockchecker.v1.GetRegistryInfoRequest\x1a(.blockchecker.v1.GetRegistryInfoResponse\"\x1d\x82\xd3\xe4\x93\x02\x17\x12\x15/api/v1/registry/info\x12\x87\x01\n" +
+	"\x10GetRegistryDiffs\x12(.blockchecker.v1.GetRegistryDiffsRequest\x1a).blockchecker.v1.GetRegistryDiffsResponse\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/api/v1/registry/diffs\x12\x8b\x01\n" +
+	"\x11GetRegistryStatus\x12).blockchecker.v1.GetRegistryStatusRequest\x1a*.blockchecker.v1.GetRegistryStatusResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/api/v1/registry/status\x12x\n" +
+	"\rWatchRegistry\x12%.blockchecker.v1.WatchRegistryRequest\x1a\x1e.blockchecker.v1.RegistryEvent\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/api/v1/registry/watch0\x012\xbe\x02\n" +
+	"\rRegistryAdmin\x12\x8e\x01\n" +
+	"\x0fRefreshRegistry\x12'.blockchecker.v1.RefreshRegistryRequest\x1a'.blockchecker.v1.RegistryUpdateResponse\")\x82\xd3\xe4\x93\x02#:\x01*\"\x1e/api/v1/admin/registry:refresh\x12\x9b\x01\n" +
+	"\x13ForceAcceptRegistry\x12+.blockchecker.v1.ForceAcceptRegistryRequest\x1a'.blockchecker.v1.RegistryUpdateResponse\".\x82\xd3\xe4\x93\x02(:\x01*\"#/api/v1/admin/registry:force-acceptB$Z\"evil-rkn/proto/gen;blockcheckerpbbb\x06proto3"
 
 var (
 	file_blockchecker_proto_rawDescOnce sync.Once
@@ -134,19 +2072,93 @@ func file_blockchecker_proto_rawDescGZIP() []byte {
 	return file_blockchecker_proto_rawDescData
 }
 
-var file_blockchecker_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_blockchecker_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_blockchecker_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
 var file_blockchecker_proto_goTypes = []any{
-	(*CheckRequest)(nil),  // 0: blockchecker.v1.CheckRequest
-	(*CheckResponse)(nil), // 1: blockchecker.v1.CheckResponse
+	(MatchKind)(0),                     // 0: blockchecker.v1.MatchKind
+	(*CheckRequest)(nil),               // 1: blockchecker.v1.CheckRequest
+	(*CheckHostRequest)(nil),           // 2: blockchecker.v1.CheckHostRequest
+	(*CheckIPRequest)(nil),             // 3: blockchecker.v1.CheckIPRequest
+	(*CheckResponse)(nil),              // 4: blockchecker.v1.CheckResponse
+	(*CheckBatchRequest)(nil),          // 5: blockchecker.v1.CheckBatchRequest
+	(*CheckBatchItem)(nil),             // 6: blockchecker.v1.CheckBatchItem
+	(*CheckBatchResponse)(nil),         // 7: blockchecker.v1.CheckBatchResponse
+	(*CheckStreamRequest)(nil),         // 8: blockchecker.v1.CheckStreamRequest
+	(*CheckStreamResult)(nil),          // 9: blockchecker.v1.CheckStreamResult
+	(*RegistryMarker)(nil),             // 10: blockchecker.v1.RegistryMarker
+	(*CheckStreamResponse)(nil),        // 11: blockchecker.v1.CheckStreamResponse
+	(*GetRegistryDiffsRequest)(nil),    // 12: blockchecker.v1.GetRegistryDiffsRequest
+	(*EntryDiff)(nil),                  // 13: blockchecker.v1.EntryDiff
+	(*RegistryDiff)(nil),               // 14: blockchecker.v1.RegistryDiff
+	(*GetRegistryDiffsResponse)(nil),   // 15: blockchecker.v1.GetRegistryDiffsResponse
+	(*WatchRegistryRequest)(nil),       // 16: blockchecker.v1.WatchRegistryRequest
+	(*RegistryEvent)(nil),              // 17: blockchecker.v1.RegistryEvent
+	(*GetRegistryStatusRequest)(nil),   // 18: blockchecker.v1.GetRegistryStatusRequest
+	(*SkipCounts)(nil),                 // 19: blockchecker.v1.SkipCounts
+	(*UpdateReport)(nil),               // 20: blockchecker.v1.UpdateReport
+	(*GetRegistryStatusResponse)(nil),  // 21: blockchecker.v1.GetRegistryStatusResponse
+	(*GetRegistryInfoRequest)(nil),     // 22: blockchecker.v1.GetRegistryInfoRequest
+	(*GetRegistryInfoResponse)(nil),    // 23: blockchecker.v1.GetRegistryInfoResponse
+	(*RefreshRegistryRequest)(nil),     // 24: blockchecker.v1.RefreshRegistryRequest
+	(*ForceAcceptRegistryRequest)(nil), // 25: blockchecker.v1.ForceAcceptRegistryRequest
+	(*RegistryUpdateResponse)(nil),     // 26: blockchecker.v1.RegistryUpdateResponse
+	(*timestamppb.Timestamp)(nil),      // 27: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),        // 28: google.protobuf.Duration
 }
 var file_blockchecker_proto_depIdxs = []int32{
-	0, // 0: blockchecker.v1.BlockChecker.Check:input_type -> blockchecker.v1.CheckRequest
-	1, // 1: blockchecker.v1.BlockChecker.Check:output_type -> blockchecker.v1.CheckResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: blockchecker.v1.CheckResponse.match_kind:type_name -> blockchecker.v1.MatchKind
+	4,  // 1: blockchecker.v1.CheckBatchItem.result:type_name -> blockchecker.v1.CheckResponse
+	6,  // 2: blockchecker.v1.CheckBatchResponse.items:type_name -> blockchecker.v1.CheckBatchItem
+	4,  // 3: blockchecker.v1.CheckStreamResult.result:type_name -> blockchecker.v1.CheckResponse
+	9,  // 4: blockchecker.v1.CheckStreamResponse.result:type_name -> blockchecker.v1.CheckStreamResult
+	10, // 5: blockchecker.v1.CheckStreamResponse.registry:type_name -> blockchecker.v1.RegistryMarker
+	27, // 6: blockchecker.v1.RegistryDiff.applied_at:type_name -> google.protobuf.Timestamp
+	13, // 7: blockchecker.v1.RegistryDiff.domains:type_name -> blockchecker.v1.EntryDiff
+	13, // 8: blockchecker.v1.RegistryDiff.urls:type_name -> blockchecker.v1.EntryDiff
+	13, // 9: blockchecker.v1.RegistryDiff.https_hosts:type_name -> blockchecker.v1.EntryDiff
+	13, // 10: blockchecker.v1.RegistryDiff.ips:type_name -> blockchecker.v1.EntryDiff
+	13, // 11: blockchecker.v1.RegistryDiff.subnets:type_name -> blockchecker.v1.EntryDiff
+	14, // 12: blockchecker.v1.GetRegistryDiffsResponse.diffs:type_name -> blockchecker.v1.RegistryDiff
+	27, // 13: blockchecker.v1.RegistryEvent.last_updated:type_name -> google.protobuf.Timestamp
+	14, // 14: blockchecker.v1.RegistryEvent.diff:type_name -> blockchecker.v1.RegistryDiff
+	27, // 15: blockchecker.v1.UpdateReport.started_at:type_name -> google.protobuf.Timestamp
+	27, // 16: blockchecker.v1.UpdateReport.finished_at:type_name -> google.protobuf.Timestamp
+	28, // 17: blockchecker.v1.UpdateReport.duration:type_name -> google.protobuf.Duration
+	19, // 18: blockchecker.v1.UpdateReport.skipped_domains:type_name -> blockchecker.v1.SkipCounts
+	19, // 19: blockchecker.v1.UpdateReport.skipped_urls:type_name -> blockchecker.v1.SkipCounts
+	19, // 20: blockchecker.v1.UpdateReport.skipped_ips:type_name -> blockchecker.v1.SkipCounts
+	27, // 21: blockchecker.v1.GetRegistryStatusResponse.last_updated:type_name -> google.protobuf.Timestamp
+	27, // 22: blockchecker.v1.GetRegistryStatusResponse.next_update:type_name -> google.protobuf.Timestamp
+	27, // 23: blockchecker.v1.GetRegistryStatusResponse.last_success:type_name -> google.protobuf.Timestamp
+	20, // 24: blockchecker.v1.GetRegistryStatusResponse.reports:type_name -> blockchecker.v1.UpdateReport
+	27, // 25: blockchecker.v1.GetRegistryInfoResponse.last_updated:type_name -> google.protobuf.Timestamp
+	1,  // 26: blockchecker.v1.BlockChecker.Check:input_type -> blockchecker.v1.CheckRequest
+	2,  // 27: blockchecker.v1.BlockChecker.CheckHost:input_type -> blockchecker.v1.CheckHostRequest
+	3,  // 28: blockchecker.v1.BlockChecker.CheckIP:input_type -> blockchecker.v1.CheckIPRequest
+	5,  // 29: blockchecker.v1.BlockChecker.CheckBatch:input_type -> blockchecker.v1.CheckBatchRequest
+	8,  // 30: blockchecker.v1.BlockChecker.CheckStream:input_type -> blockchecker.v1.CheckStreamRequest
+	22, // 31: blockchecker.v1.BlockChecker.GetRegistryInfo:input_type -> blockchecker.v1.GetRegistryInfoRequest
+	12, // 32: blockchecker.v1.BlockChecker.GetRegistryDiffs:input_type -> blockchecker.v1.GetRegistryDiffsRequest
+	18, // 33: blockchecker.v1.BlockChecker.GetRegistryStatus:input_type -> blockchecker.v1.GetRegistryStatusRequest
+	16, // 34: blockchecker.v1.BlockChecker.WatchRegistry:input_type -> blockchecker.v1.WatchRegistryRequest
+	24, // 35: blockchecker.v1.RegistryAdmin.RefreshRegistry:input_type -> blockchecker.v1.RefreshRegistryRequest
+	25, // 36: blockchecker.v1.RegistryAdmin.ForceAcceptRegistry:input_type -> blockchecker.v1.ForceAcceptRegistryRequest
+	4,  // 37: blockchecker.v1.BlockChecker.Check:output_type -> blockchecker.v1.CheckResponse
+	4,  // 38: blockchecker.v1.BlockChecker.CheckHost:output_type -> blockchecker.v1.CheckResponse
+	4,  // 39: blockchecker.v1.BlockChecker.CheckIP:output_type -> blockchecker.v1.CheckResponse
+	7,  // 40: blockchecker.v1.BlockChecker.CheckBatch:output_type -> blockchecker.v1.CheckBatchResponse
+	11, // 41: blockchecker.v1.BlockChecker.CheckStream:output_type -> blockchecker.v1.CheckStreamResponse
+	23, // 42: blockchecker.v1.BlockChecker.GetRegistryInfo:output_type -> blockchecker.v1.GetRegistryInfoResponse
+	15, // 43: blockchecker.v1.BlockChecker.GetRegistryDiffs:output_type -> blockchecker.v1.GetRegistryDiffsResponse
+	21, // 44: blockchecker.v1.BlockChecker.GetRegistryStatus:output_type -> blockchecker.v1.GetRegistryStatusResponse
+	17, // 45: blockchecker.v1.BlockChecker.WatchRegistry:output_type -> blockchecker.v1.RegistryEvent
+	26, // 46: blockchecker.v1.RegistryAdmin.RefreshRegistry:output_type -> blockchecker.v1.RegistryUpdateResponse
+	26, // 47: blockchecker.v1.RegistryAdmin.ForceAcceptRegistry:output_type -> blockchecker.v1.RegistryUpdateResponse
+	37, // [37:48] is the sub-list for method output_type
+	26, // [26:37] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
 }
 
 func init() { file_blockchecker_proto_init() }
@@ -154,18 +2166,24 @@ func file_blockchecker_proto_init() {
 	if File_blockchecker_proto != nil {
 		return
 	}
+	file_blockchecker_proto_msgTypes[3].OneofWrappers = []any{}
+	file_blockchecker_proto_msgTypes[10].OneofWrappers = []any{
+		(*CheckStreamResponse_Result)(nil),
+		(*CheckStreamResponse_Registry)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_blockchecker_proto_rawDesc), len(file_blockchecker_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      1,
+			NumMessages:   26,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_blockchecker_proto_goTypes,
 		DependencyIndexes: file_blockchecker_proto_depIdxs,
+		EnumInfos:         file_blockchecker_proto_enumTypes,
 		MessageInfos:      file_blockchecker_proto_msgTypes,
 	}.Build()
 	File_blockchecker_proto = out.File
@@ -19,14 +19,43 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	BlockChecker_Check_FullMethodName = "/blockchecker.v1.BlockChecker/Check"
+	BlockChecker_Check_FullMethodName             = "/blockchecker.v1.BlockChecker/Check"
+	BlockChecker_CheckHost_FullMethodName         = "/blockchecker.v1.BlockChecker/CheckHost"
+	BlockChecker_CheckIP_FullMethodName           = "/blockchecker.v1.BlockChecker/CheckIP"
+	BlockChecker_CheckBatch_FullMethodName        = "/blockchecker.v1.BlockChecker/CheckBatch"
+	BlockChecker_CheckStream_FullMethodName       = "/blockchecker.v1.BlockChecker/CheckStream"
+	BlockChecker_GetRegistryInfo_FullMethodName   = "/blockchecker.v1.BlockChecker/GetRegistryInfo"
+	BlockChecker_GetRegistryDiffs_FullMethodName  = "/blockchecker.v1.BlockChecker/GetRegistryDiffs"
+	BlockChecker_GetRegistryStatus_FullMethodName = "/blockchecker.v1.BlockChecker/GetRegistryStatus"
+	BlockChecker_WatchRegistry_FullMethodName     = "/blockchecker.v1.BlockChecker/WatchRegistry"
 )
 
 // BlockCheckerClient is the client API for BlockChecker service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Check responses carry the x-registry-version and x-registry-fingerprint
+// headers of the registry that answered (Grpc-Metadata-X-Registry-* over HTTP).
 type BlockCheckerClient interface {
 	Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error)
+	// Checks a host without URL: domain, https host and, for IP literals,
+	// IP entries.
+	CheckHost(ctx context.Context, in *CheckHostRequest, opts ...grpc.CallOption) (*CheckResponse, error)
+	// Checks an address against the IP and subnet entries only.
+	CheckIP(ctx context.Context, in *CheckIPRequest, opts ...grpc.CallOption) (*CheckResponse, error)
+	// Checks many URLs against one registry version. Invalid URLs fail
+	// individually, not the whole call.
+	CheckBatch(ctx context.Context, in *CheckBatchRequest, opts ...grpc.CallOption) (*CheckBatchResponse, error)
+	// Checks URLs as the client sends them, results come back in order.
+	CheckStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CheckStreamRequest, CheckStreamResponse], error)
+	// Size, origin and fingerprint of the registry being served.
+	GetRegistryInfo(ctx context.Context, in *GetRegistryInfoRequest, opts ...grpc.CallOption) (*GetRegistryInfoResponse, error)
+	// What the recent registry updates added and removed.
+	GetRegistryDiffs(ctx context.Context, in *GetRegistryDiffsRequest, opts ...grpc.CallOption) (*GetRegistryDiffsResponse, error)
+	// Update schedule and ingestion reports of the recent update attempts.
+	GetRegistryStatus(ctx context.Context, in *GetRegistryStatusRequest, opts ...grpc.CallOption) (*GetRegistryStatusResponse, error)
+	// Streams the current registry version and then every change with its diff.
+	WatchRegistry(ctx context.Context, in *WatchRegistryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RegistryEvent], error)
 }
 
 type blockCheckerClient struct {
@@ -47,11 +76,124 @@ func (c *blockCheckerClient) Check(ctx context.Context, in *CheckRequest, opts .
 	return out, nil
 }
 
+func (c *blockCheckerClient) CheckHost(ctx context.Context, in *CheckHostRequest, opts ...grpc.CallOption) (*CheckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckResponse)
+	err := c.cc.Invoke(ctx, BlockChecker_CheckHost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockCheckerClient) CheckIP(ctx context.Context, in *CheckIPRequest, opts ...grpc.CallOption) (*CheckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckResponse)
+	err := c.cc.Invoke(ctx, BlockChecker_CheckIP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockCheckerClient) CheckBatch(ctx context.Context, in *CheckBatchRequest, opts ...grpc.CallOption) (*CheckBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckBatchResponse)
+	err := c.cc.Invoke(ctx, BlockChecker_CheckBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockCheckerClient) CheckStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CheckStreamRequest, CheckStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BlockChecker_ServiceDesc.Streams[0], BlockChecker_CheckStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CheckStreamRequest, CheckStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlockChecker_CheckStreamClient = grpc.BidiStreamingClient[CheckStreamRequest, CheckStreamResponse]
+
+func (c *blockCheckerClient) GetRegistryInfo(ctx context.Context, in *GetRegistryInfoRequest, opts ...grpc.CallOption) (*GetRegistryInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRegistryInfoResponse)
+	err := c.cc.Invoke(ctx, BlockChecker_GetRegistryInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockCheckerClient) GetRegistryDiffs(ctx context.Context, in *GetRegistryDiffsRequest, opts ...grpc.CallOption) (*GetRegistryDiffsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRegistryDiffsResponse)
+	err := c.cc.Invoke(ctx, BlockChecker_GetRegistryDiffs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockCheckerClient) GetRegistryStatus(ctx context.Context, in *GetRegistryStatusRequest, opts ...grpc.CallOption) (*GetRegistryStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRegistryStatusResponse)
+	err := c.cc.Invoke(ctx, BlockChecker_GetRegistryStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockCheckerClient) WatchRegistry(ctx context.Context, in *WatchRegistryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RegistryEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BlockChecker_ServiceDesc.Streams[1], BlockChecker_WatchRegistry_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRegistryRequest, RegistryEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlockChecker_WatchRegistryClient = grpc.ServerStreamingClient[RegistryEvent]
+
 // BlockCheckerServer is the server API for BlockChecker service.
 // All implementations must embed UnimplementedBlockCheckerServer
 // for forward compatibility.
+//
+// Check responses carry the x-registry-version and x-registry-fingerprint
+// headers of the registry that answered (Grpc-Metadata-X-Registry-* over HTTP).
 type BlockCheckerServer interface {
 	Check(context.Context, *CheckRequest) (*CheckResponse, error)
+	// Checks a host without URL: domain, https host and, for IP literals,
+	// IP entries.
+	CheckHost(context.Context, *CheckHostRequest) (*CheckResponse, error)
+	// Checks an address against the IP and subnet entries only.
+	CheckIP(context.Context, *CheckIPRequest) (*CheckResponse, error)
+	// Checks many URLs against one registry version. Invalid URLs fail
+	// individually, not the whole call.
+	CheckBatch(context.Context, *CheckBatchRequest) (*CheckBatchResponse, error)
+	// Checks URLs as the client sends them, results come back in order.
+	CheckStream(grpc.BidiStreamingServer[CheckStreamRequest, CheckStreamResponse]) error
+	// Size, origin and fingerprint of the registry being served.
+	GetRegistryInfo(context.Context, *GetRegistryInfoRequest) (*GetRegistryInfoResponse, error)
+	// What the recent registry updates added and removed.
+	GetRegistryDiffs(context.Context, *GetRegistryDiffsRequest) (*GetRegistryDiffsResponse, error)
+	// Update schedule and ingestion reports of the recent update attempts.
+	GetRegistryStatus(context.Context, *GetRegistryStatusRequest) (*GetRegistryStatusResponse, error)
+	// Streams the current registry version and then every change with its diff.
+	WatchRegistry(*WatchRegistryRequest, grpc.ServerStreamingServer[RegistryEvent]) error
 	mustEmbedUnimplementedBlockCheckerServer()
 }
 
@@ -65,6 +207,30 @@ type UnimplementedBlockCheckerServer struct{}
 func (UnimplementedBlockCheckerServer) Check(context.Context, *CheckRequest) (*CheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
 }
+func (UnimplementedBlockCheckerServer) CheckHost(context.Context, *CheckHostRequest) (*CheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckHost not implemented")
+}
+func (UnimplementedBlockCheckerServer) CheckIP(context.Context, *CheckIPRequest) (*CheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckIP not implemented")
+}
+func (UnimplementedBlockCheckerServer) CheckBatch(context.Context, *CheckBatchRequest) (*CheckBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckBatch not implemented")
+}
+func (UnimplementedBlockCheckerServer) CheckStream(grpc.BidiStreamingServer[CheckStreamRequest, CheckStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method CheckStream not implemented")
+}
+func (UnimplementedBlockCheckerServer) GetRegistryInfo(context.Context, *GetRegistryInfoRequest) (*GetRegistryInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRegistryInfo not implemented")
+}
+func (UnimplementedBlockCheckerServer) GetRegistryDiffs(context.Context, *GetRegistryDiffsRequest) (*GetRegistryDiffsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRegistryDiffs not implemented")
+}
+func (UnimplementedBlockCheckerServer) GetRegistryStatus(context.Context, *GetRegistryStatusRequest) (*GetRegistryStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRegistryStatus not implemented")
+}
+func (UnimplementedBlockCheckerServer) WatchRegistry(*WatchRegistryRequest, grpc.ServerStreamingServer[RegistryEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRegistry not implemented")
+}
 func (UnimplementedBlockCheckerServer) mustEmbedUnimplementedBlockCheckerServer() {}
 func (UnimplementedBlockCheckerServer) testEmbeddedByValue()                      {}
 
@@ -104,6 +270,132 @@ func _BlockChecker_Check_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlockChecker_CheckHost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckHostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockCheckerServer).CheckHost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlockChecker_CheckHost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockCheckerServer).CheckHost(ctx, req.(*CheckHostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockChecker_CheckIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckIPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockCheckerServer).CheckIP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlockChecker_CheckIP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockCheckerServer).CheckIP(ctx, req.(*CheckIPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockChecker_CheckBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockCheckerServer).CheckBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlockChecker_CheckBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockCheckerServer).CheckBatch(ctx, req.(*CheckBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockChecker_CheckStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BlockCheckerServer).CheckStream(&grpc.GenericServerStream[CheckStreamRequest, CheckStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlockChecker_CheckStreamServer = grpc.BidiStreamingServer[CheckStreamRequest, CheckStreamResponse]
+
+func _BlockChecker_GetRegistryInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRegistryInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockCheckerServer).GetRegistryInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlockChecker_GetRegistryInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockCheckerServer).GetRegistryInfo(ctx, req.(*GetRegistryInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockChecker_GetRegistryDiffs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRegistryDiffsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockCheckerServer).GetRegistryDiffs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlockChecker_GetRegistryDiffs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockCheckerServer).GetRegistryDiffs(ctx, req.(*GetRegistryDiffsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockChecker_GetRegistryStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRegistryStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockCheckerServer).GetRegistryStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlockChecker_GetRegistryStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockCheckerServer).GetRegistryStatus(ctx, req.(*GetRegistryStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockChecker_WatchRegistry_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRegistryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlockCheckerServer).WatchRegistry(m, &grpc.GenericServerStream[WatchRegistryRequest, RegistryEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BlockChecker_WatchRegistryServer = grpc.ServerStreamingServer[RegistryEvent]
+
 // BlockChecker_ServiceDesc is the grpc.ServiceDesc for BlockChecker service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,6 +407,190 @@ var BlockChecker_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Check",
 			Handler:    _BlockChecker_Check_Handler,
 		},
+		{
+			MethodName: "CheckHost",
+			Handler:    _BlockChecker_CheckHost_Handler,
+		},
+		{
+			MethodName: "CheckIP",
+			Handler:    _BlockChecker_CheckIP_Handler,
+		},
+		{
+			MethodName: "CheckBatch",
+			Handler:    _BlockChecker_CheckBatch_Handler,
+		},
+		{
+			MethodName: "GetRegistryInfo",
+			Handler:    _BlockChecker_GetRegistryInfo_Handler,
+		},
+		{
+			MethodName: "GetRegistryDiffs",
+			Handler:    _BlockChecker_GetRegistryDiffs_Handler,
+		},
+		{
+			MethodName: "GetRegistryStatus",
+			Handler:    _BlockChecker_GetRegistryStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CheckStream",
+			Handler:       _BlockChecker_CheckStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchRegistry",
+			Handler:       _BlockChecker_WatchRegistry_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "blockchecker.proto",
+}
+
+const (
+	RegistryAdmin_RefreshRegistry_FullMethodName     = "/blockchecker.v1.RegistryAdmin/RefreshRegistry"
+	RegistryAdmin_ForceAcceptRegistry_FullMethodName = "/blockchecker.v1.RegistryAdmin/ForceAcceptRegistry"
+)
+
+// RegistryAdminClient is the client API for RegistryAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Operator actions, authorized with "authorization: Bearer <ADMIN_TOKEN>".
+type RegistryAdminClient interface {
+	// Fetches the registry now instead of waiting for the next scheduled update.
+	RefreshRegistry(ctx context.Context, in *RefreshRegistryRequest, opts ...grpc.CallOption) (*RegistryUpdateResponse, error)
+	// Swaps in the last update rejected by the sanity rules.
+	ForceAcceptRegistry(ctx context.Context, in *ForceAcceptRegistryRequest, opts ...grpc.CallOption) (*RegistryUpdateResponse, error)
+}
+
+type registryAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRegistryAdminClient(cc grpc.ClientConnInterface) RegistryAdminClient {
+	return &registryAdminClient{cc}
+}
+
+func (c *registryAdminClient) RefreshRegistry(ctx context.Context, in *RefreshRegistryRequest, opts ...grpc.CallOption) (*RegistryUpdateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegistryUpdateResponse)
+	err := c.cc.Invoke(ctx, RegistryAdmin_RefreshRegistry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registryAdminClient) ForceAcceptRegistry(ctx context.Context, in *ForceAcceptRegistryRequest, opts ...grpc.CallOption) (*RegistryUpdateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegistryUpdateResponse)
+	err := c.cc.Invoke(ctx, RegistryAdmin_ForceAcceptRegistry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RegistryAdminServer is the server API for RegistryAdmin service.
+// All implementations must embed UnimplementedRegistryAdminServer
+// for forward compatibility.
+//
+// Operator actions, authorized with "authorization: Bearer <ADMIN_TOKEN>".
+type RegistryAdminServer interface {
+	// Fetches the registry now instead of waiting for the next scheduled update.
+	RefreshRegistry(context.Context, *RefreshRegistryRequest) (*RegistryUpdateResponse, error)
+	// Swaps in the last update rejected by the sanity rules.
+	ForceAcceptRegistry(context.Context, *ForceAcceptRegistryRequest) (*RegistryUpdateResponse, error)
+	mustEmbedUnimplementedRegistryAdminServer()
+}
+
+// UnimplementedRegistryAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRegistryAdminServer struct{}
+
+func (UnimplementedRegistryAdminServer) RefreshRegistry(context.Context, *RefreshRegistryRequest) (*RegistryUpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshRegistry not implemented")
+}
+func (UnimplementedRegistryAdminServer) ForceAcceptRegistry(context.Context, *ForceAcceptRegistryRequest) (*RegistryUpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForceAcceptRegistry not implemented")
+}
+func (UnimplementedRegistryAdminServer) mustEmbedUnimplementedRegistryAdminServer() {}
+func (UnimplementedRegistryAdminServer) testEmbeddedByValue()                       {}
+
+// UnsafeRegistryAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RegistryAdminServer will
+// result in compilation errors.
+type UnsafeRegistryAdminServer interface {
+	mustEmbedUnimplementedRegistryAdminServer()
+}
+
+func RegisterRegistryAdminServer(s grpc.ServiceRegistrar, srv RegistryAdminServer) {
+	// If the following call pancis, it indicates UnimplementedRegistryAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RegistryAdmin_ServiceDesc, srv)
+}
+
+func _RegistryAdmin_RefreshRegistry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRegistryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryAdminServer).RefreshRegistry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RegistryAdmin_RefreshRegistry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryAdminServer).RefreshRegistry(ctx, req.(*RefreshRegistryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RegistryAdmin_ForceAcceptRegistry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForceAcceptRegistryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistryAdminServer).ForceAcceptRegistry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RegistryAdmin_ForceAcceptRegistry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistryAdminServer).ForceAcceptRegistry(ctx, req.(*ForceAcceptRegistryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RegistryAdmin_ServiceDesc is the grpc.ServiceDesc for RegistryAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RegistryAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "blockchecker.v1.RegistryAdmin",
+	HandlerType: (*RegistryAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RefreshRegistry",
+			Handler:    _RegistryAdmin_RefreshRegistry_Handler,
+		},
+		{
+			MethodName: "ForceAcceptRegistry",
+			Handler:    _RegistryAdmin_ForceAcceptRegistry_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "blockchecker.proto",